@@ -1 +1 @@
-../../go/bin/go build -o ../build-debug/
+../../go/bin/go build -o ../build-debug/ ./cmd/formatter
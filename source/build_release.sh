@@ -1 +1 @@
-../../go/bin/go build -ldflags "-s -w" -o ../build-release/
+../../go/bin/go build -ldflags "-s -w" -o ../build-release/ ./cmd/formatter
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/format"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Special revision value for reading diff from stdin.
+const ChangedLinesFromStdin = "-"
+
+// Get lines of given file, changed since given git revision, using git binary.
+func GetGitChangedLines(file_name string, revision string) ([]format.LineRange, error) {
+
+	command := exec.Command(
+		"git",
+		"-C", filepath.Dir(file_name),
+		"diff", "--no-color", "--no-ext-diff", "-U0", revision, "--", filepath.Base(file_name))
+
+	stderr := bytes.Buffer{}
+	command.Stderr = &stderr
+
+	output, err := command.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %s %s", err.Error(), strings.TrimSpace(stderr.String()))
+	}
+
+	result := make([]format.LineRange, 0)
+	for _, ranges := range ParseDiffChangedLines(string(output)) {
+		result = append(result, ranges...)
+	}
+
+	return result, nil
+}
+
+// Get root directory of git repository, containing given directory.
+// Result is relative path to the root joined with given directory, in order to keep symlinks in it.
+func GetGitTopLevelDirectory(dir string) (string, error) {
+	command := exec.Command("git", "-C", dir, "rev-parse", "--show-cdup")
+
+	stderr := bytes.Buffer{}
+	command.Stderr = &stderr
+
+	output, err := command.Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse failed: %s %s", err.Error(), strings.TrimSpace(stderr.String()))
+	}
+
+	return filepath.Join(dir, strings.TrimSpace(string(output))), nil
+}
+
+// Convert file names from diff into absolute paths.
+// Relative paths are resolved against given base directory.
+func ResolveDiffFileNames(changed_lines map[string][]format.LineRange, base_dir string) map[string][]format.LineRange {
+	result := make(map[string][]format.LineRange)
+	for file_name, ranges := range changed_lines {
+		if !filepath.IsAbs(file_name) {
+			file_name = filepath.Join(base_dir, file_name)
+		}
+		result[filepath.Clean(file_name)] = ranges
+	}
+	return result
+}
+
+// Parse unified diff and get ranges of added lines in new versions of files.
+// Result keys are file names from "+++" headers, without "b/" prefix.
+func ParseDiffChangedLines(diff_text string) map[string][]format.LineRange {
+
+	result := make(map[string][]format.LineRange)
+
+	file_name := ""
+	new_line := uint(0)
+	old_lines_left := uint(0)
+	new_lines_left := uint(0)
+
+	add_line := func(line uint) {
+		ranges := result[file_name]
+		if len(ranges) > 0 && ranges[len(ranges)-1].Last+1 == line {
+			ranges[len(ranges)-1].Last = line
+		} else {
+			ranges = append(ranges, format.LineRange{First: line, Last: line})
+		}
+		result[file_name] = ranges
+	}
+
+	for _, line := range strings.Split(diff_text, "\n") {
+
+		if old_lines_left > 0 || new_lines_left > 0 {
+			// Inside hunk body.
+			// Counters are checked, since broken diffs may contain more lines than specified in hunk header.
+			if strings.HasPrefix(line, "+") && new_lines_left > 0 {
+				if file_name != "" {
+					add_line(new_line)
+				}
+				new_line++
+				new_lines_left--
+				continue
+			} else if strings.HasPrefix(line, "-") && old_lines_left > 0 {
+				old_lines_left--
+				continue
+			} else if (strings.HasPrefix(line, " ") || line == "") && old_lines_left > 0 && new_lines_left > 0 {
+				new_line++
+				old_lines_left--
+				new_lines_left--
+				continue
+			} else if strings.HasPrefix(line, "\\") {
+				// Lines like "\ No newline at end of file" are ignored.
+				continue
+			}
+
+			// Unexpected line - hunk is broken, process this line as header.
+			old_lines_left = 0
+			new_lines_left = 0
+		}
+
+		if strings.HasPrefix(line, "+++ ") {
+			file_name = parseDiffFileName(line[4:])
+			if file_name != "" {
+				result[file_name] = result[file_name] // Register file even if it has no added lines.
+			}
+		} else if strings.HasPrefix(line, "@@ ") {
+			old_count, new_start, new_count, ok := parseHunkHeader(line)
+			if ok {
+				new_line = new_start
+				old_lines_left = old_count
+				new_lines_left = new_count
+			}
+		}
+	}
+
+	return result
+}
+
+// Returns empty string for "/dev/null".
+func parseDiffFileName(s string) string {
+	// Remove timestamp after tab.
+	s, _, _ = strings.Cut(s, "\t")
+
+	if strings.HasPrefix(s, "\"") {
+		unquoted, err := strconv.Unquote(s)
+		if err == nil {
+			s = unquoted
+		}
+	}
+
+	if s == "/dev/null" {
+		return ""
+	}
+
+	return strings.TrimPrefix(s, "b/")
+}
+
+// Parse header like "@@ -1,2 +3,4 @@". Returns old lines count, new lines start and count.
+func parseHunkHeader(line string) (uint, uint, uint, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, false
+	}
+
+	_, old_count, old_ok := parseHunkRange(fields[1][1:])
+	new_start, new_count, new_ok := parseHunkRange(fields[2][1:])
+
+	return old_count, new_start, new_count, old_ok && new_ok
+}
+
+// Parse range like "start,count" or "start".
+func parseHunkRange(s string) (uint, uint, bool) {
+	start_str, count_str, has_count := strings.Cut(s, ",")
+
+	start, err := strconv.ParseUint(start_str, 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	count := uint64(1)
+	if has_count {
+		count, err = strconv.ParseUint(count_str, 10, 32)
+		if err != nil {
+			return 0, 0, false
+		}
+	}
+
+	return uint(start), uint(count), true
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/format"
+	"reflect"
+	"testing"
+)
+
+func TestParseDiffChangedLines(t *testing.T) {
+	test_cases := []struct {
+		name     string
+		diff     string
+		expected map[string][]format.LineRange
+	}{
+		{
+			name:     "empty",
+			diff:     "",
+			expected: map[string][]format.LineRange{},
+		},
+		{
+			name: "zero context",
+			diff: `diff --git a/a.u b/a.u
+--- a/a.u
++++ b/a.u
+@@ -2 +2 @@
+-old
++new
+@@ -10,0 +11,3 @@
++x
++y
++z
+@@ -20,2 +23,0 @@
+-removed
+-removed
+`,
+			expected: map[string][]format.LineRange{"a.u": {{First: 2, Last: 2}, {First: 11, Last: 13}}},
+		},
+		{
+			name: "with context",
+			diff: `--- a/a.u
++++ b/a.u
+@@ -1,4 +1,5 @@
+ first
+-second
++second changed
++inserted
+ third
+ fourth
+`,
+			expected: map[string][]format.LineRange{"a.u": {{First: 2, Last: 3}}},
+		},
+		{
+			name: "several files",
+			diff: `--- a/a.u
++++ b/a.u
+@@ -1 +1 @@
+-a
+\ No newline at end of file
++b
+\ No newline at end of file
+--- a/dir/b.u
++++ b/dir/b.u
+@@ -3 +2,0 @@
+-deleted
+--- a/c.u
++++ /dev/null
+@@ -1 +0,0 @@
+-c
+--- /dev/null
++++ b/new.u
+@@ -0,0 +1,2 @@
++n1
++n2
+`,
+			expected: map[string][]format.LineRange{
+				"a.u":     {{First: 1, Last: 1}},
+				"dir/b.u": nil,
+				"new.u":   {{First: 1, Last: 2}},
+			},
+		},
+		{
+			name: "quoted file name",
+			diff: `--- "a/with space.u"
++++ "b/with space.u"
+@@ -1 +1 @@
+-a
++b
+`,
+			expected: map[string][]format.LineRange{"with space.u": {{First: 1, Last: 1}}},
+		},
+		{
+			name: "too many removed lines in hunk",
+			diff: `--- a/a.u
++++ b/a.u
+@@ -1 +1 @@
+-a
+-b
++c
+ e
+--- a/b.u
++++ b/b.u
+@@ -1,0 +2 @@
++x
+`,
+			expected: map[string][]format.LineRange{"a.u": nil, "b.u": {{First: 2, Last: 2}}},
+		},
+		{
+			name: "too many added lines in hunk",
+			diff: `--- a/a.u
++++ b/a.u
+@@ -1,2 +1 @@
+-a
++b
++c
+ d
+`,
+			expected: map[string][]format.LineRange{"a.u": {{First: 1, Last: 1}}},
+		},
+	}
+
+	for _, test_case := range test_cases {
+		result := ParseDiffChangedLines(test_case.diff)
+		if !reflect.DeepEqual(result, test_case.expected) {
+			t.Errorf("%s: got %v, expected %v", test_case.name, result, test_case.expected)
+		}
+	}
+}
+
+func TestResolveDiffFileNames(t *testing.T) {
+	changed_lines := map[string][]format.LineRange{
+		"a.u":          {{First: 1, Last: 1}},
+		"dir/../b.u":   {{First: 2, Last: 3}},
+		"/abs/dir/c.u": nil,
+	}
+
+	expected := map[string][]format.LineRange{
+		"/root/a.u":    {{First: 1, Last: 1}},
+		"/root/b.u":    {{First: 2, Last: 3}},
+		"/abs/dir/c.u": nil,
+	}
+
+	result := ResolveDiffFileNames(changed_lines, "/root")
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %v, expected %v", result, expected)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Replace file contents atomically - write new contents into temporary file first and than rename it.
+// File permissions are preserved. If file is a symlink, its target is replaced.
+func WriteFileAtomically(file_name string, contents []byte) error {
+
+	file_name, err := filepath.EvalSymlinks(file_name)
+	if err != nil {
+		return err
+	}
+
+	stat, err := os.Stat(file_name)
+	if err != nil {
+		return err
+	}
+
+	temp_file, err := os.CreateTemp(filepath.Dir(file_name), filepath.Base(file_name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	temp_file_name := temp_file.Name()
+
+	_, err = temp_file.Write(contents)
+	if err == nil {
+		err = temp_file.Sync()
+	}
+	close_err := temp_file.Close()
+	if err == nil {
+		err = close_err
+	}
+	if err == nil {
+		err = os.Chmod(temp_file_name, stat.Mode().Perm())
+	}
+	if err == nil {
+		err = os.Rename(temp_file_name, file_name)
+	}
+
+	if err != nil {
+		os.Remove(temp_file_name)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Directories, which are skipped by default. Usually they contain generated files or version control data.
+var DefaultExcludedDirectoryPatterns = []string{".*", "build", "build-*"}
+
+type FilesCollector struct {
+	extensions       []string
+	exclude_patterns []string
+	visited_dirs     map[string]bool // Real paths of visited directories, used to avoid symlink loops.
+	files            []string
+	errors           []FileError
+}
+
+type FileError struct {
+	file_name string
+	err       error
+}
+
+func NewFilesCollector(extensions []string, exclude_patterns []string) *FilesCollector {
+	return &FilesCollector{
+		extensions:       extensions,
+		exclude_patterns: exclude_patterns,
+		visited_dirs:     make(map[string]bool)}
+}
+
+// Add file or directory given in command line.
+// Files are always added, directories are walked recursively.
+func (collector *FilesCollector) AddPath(file_name string) {
+
+	if file_name == StdinFileName {
+		collector.files = append(collector.files, file_name)
+		return
+	}
+
+	stat, err := os.Stat(file_name)
+	if err != nil {
+		collector.errors = append(collector.errors, FileError{file_name: file_name, err: err})
+		return
+	}
+
+	if !stat.IsDir() {
+		collector.files = append(collector.files, file_name)
+		return
+	}
+
+	patterns := make([]IgnorePattern, 0)
+	for _, exclude_pattern := range DefaultExcludedDirectoryPatterns {
+		pattern, _ := ParseIgnorePattern(exclude_pattern+"/", "")
+		patterns = append(patterns, pattern)
+	}
+	for _, exclude_pattern := range collector.exclude_patterns {
+		pattern, ok := ParseIgnorePattern(exclude_pattern, "")
+		if ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	collector.walkDirectory(file_name, "", patterns)
+}
+
+func (collector *FilesCollector) walkDirectory(dir_path string, rel_path string, patterns []IgnorePattern) {
+
+	real_path, err := filepath.EvalSymlinks(dir_path)
+	if err == nil {
+		real_path, err = filepath.Abs(real_path)
+	}
+	if err != nil {
+		collector.errors = append(collector.errors, FileError{file_name: dir_path, err: err})
+		return
+	}
+	if collector.visited_dirs[real_path] {
+		return // Symlink loop or directory already visited via other path.
+	}
+	collector.visited_dirs[real_path] = true
+
+	ignore_file_patterns, err := ReadIgnoreFile(filepath.Join(dir_path, IgnoreFileName), rel_path)
+	if err != nil {
+		collector.errors = append(collector.errors, FileError{file_name: filepath.Join(dir_path, IgnoreFileName), err: err})
+	}
+	if len(ignore_file_patterns) > 0 {
+		// Avoid modifying patterns list of the parent directory.
+		patterns = append(patterns[:len(patterns):len(patterns)], ignore_file_patterns...)
+	}
+
+	entries, err := os.ReadDir(dir_path)
+	if err != nil {
+		collector.errors = append(collector.errors, FileError{file_name: dir_path, err: err})
+		return
+	}
+
+	for _, entry := range entries {
+		entry_path := filepath.Join(dir_path, entry.Name())
+		entry_rel_path := path.Join(rel_path, entry.Name())
+
+		is_dir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			// Follow symlinks.
+			stat, err := os.Stat(entry_path)
+			if err != nil {
+				collector.errors = append(collector.errors, FileError{file_name: entry_path, err: err})
+				continue
+			}
+			is_dir = stat.IsDir()
+		}
+
+		if IsPathIgnored(patterns, entry_rel_path, is_dir) {
+			continue
+		}
+
+		if is_dir {
+			collector.walkDirectory(entry_path, entry_rel_path, patterns)
+		} else if collector.hasSourceExtension(entry.Name()) {
+			collector.files = append(collector.files, entry_path)
+		}
+	}
+}
+
+func (collector *FilesCollector) hasSourceExtension(file_name string) bool {
+	for _, extension := range collector.extensions {
+		if strings.HasSuffix(file_name, extension) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+)
+
+const IgnoreFileName = ".uformatignore"
+
+// Pattern with gitignore-like semantics.
+type IgnorePattern struct {
+	base_dir string   // Slash-separated directory path, relative to walk root. Pattern affects only paths inside it.
+	segments []string // Pattern, splitted by "/".
+	negated  bool     // Pattern starts with "!" - re-include matching paths.
+	dir_only bool     // Pattern ends with "/" - match only directories.
+	anchored bool     // Pattern contains "/" - match path relative to base directory, not only base name.
+}
+
+// Parse single pattern line. Returns false for empty lines and comments.
+func ParseIgnorePattern(line string, base_dir string) (IgnorePattern, bool) {
+
+	line = strings.TrimRight(line, " \t\r")
+	if len(line) == 0 || line[0] == '#' {
+		return IgnorePattern{}, false
+	}
+
+	pattern := IgnorePattern{base_dir: base_dir}
+
+	if line[0] == '!' {
+		pattern.negated = true
+		line = line[1:]
+	} else if line[0] == '\\' {
+		// Escaped leading "#" or "!".
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		pattern.dir_only = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if strings.Contains(line, "/") {
+		pattern.anchored = true
+		line = strings.TrimLeft(line, "/")
+	}
+
+	if len(line) == 0 {
+		return IgnorePattern{}, false
+	}
+
+	pattern.segments = strings.Split(line, "/")
+
+	return pattern, true
+}
+
+// Read patterns from ignore file. Missing file is not an error.
+func ReadIgnoreFile(file_name string, base_dir string) ([]IgnorePattern, error) {
+	file, err := os.Open(file_name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	result := make([]IgnorePattern, 0)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pattern, ok := ParseIgnorePattern(scanner.Text(), base_dir)
+		if ok {
+			result = append(result, pattern)
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// Check if given slash-separated path (relative to walk root) is ignored.
+// Last matching pattern wins.
+func IsPathIgnored(patterns []IgnorePattern, rel_path string, is_dir bool) bool {
+	ignored := false
+	for i := range patterns {
+		if patterns[i].Matches(rel_path, is_dir) {
+			ignored = !patterns[i].negated
+		}
+	}
+	return ignored
+}
+
+func (pattern *IgnorePattern) Matches(rel_path string, is_dir bool) bool {
+
+	if pattern.dir_only && !is_dir {
+		return false
+	}
+
+	if pattern.base_dir != "" {
+		if !strings.HasPrefix(rel_path, pattern.base_dir+"/") {
+			return false
+		}
+		rel_path = rel_path[len(pattern.base_dir)+1:]
+	}
+
+	if pattern.anchored {
+		return MatchGlobSegments(pattern.segments, strings.Split(rel_path, "/"))
+	}
+
+	return MatchGlobSegments(pattern.segments, []string{path.Base(rel_path)})
+}
+
+// Match path segments against pattern segments.
+// "**" matches zero or more segments, trailing "**" matches one or more segments.
+func MatchGlobSegments(pattern []string, segments []string) bool {
+
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		min_segments := 0
+		if len(pattern) == 1 {
+			min_segments = 1
+		}
+		for i := min_segments; i <= len(segments); i++ {
+			if MatchGlobSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	matched, err := path.Match(pattern[0], segments[0])
+	if err != nil || !matched {
+		return false
+	}
+
+	return MatchGlobSegments(pattern[1:], segments[1:])
+}
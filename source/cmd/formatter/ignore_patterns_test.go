@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchGlobSegments(t *testing.T) {
+	test_cases := []struct {
+		pattern string
+		path    string
+		matches bool
+	}{
+		{"a", "a", true},
+		{"a", "b", false},
+		{"a/b", "a/b", true},
+		{"a/b", "a", false},
+		{"a", "a/b", false},
+		{"*.u", "main.u", true},
+		{"*.u", "main.uh", false},
+		{"src/*.u", "src/main.u", true},
+		{"src/*.u", "src/sub/main.u", false},
+		{"**/main.u", "main.u", true},
+		{"**/main.u", "a/b/main.u", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/y/c", false},
+		{"a/**", "a/b", true},
+		{"a/**", "a/b/c", true},
+		{"a/**", "a", false}, // Trailing "**" requires at least one segment.
+		{"build-*", "build-release", true},
+		{"[ab].u", "b.u", true},
+		{"[ab].u", "c.u", false},
+	}
+
+	for _, test_case := range test_cases {
+		result := MatchGlobSegments(strings.Split(test_case.pattern, "/"), strings.Split(test_case.path, "/"))
+		if result != test_case.matches {
+			t.Errorf("pattern %q, path %q: got %v, expected %v", test_case.pattern, test_case.path, result, test_case.matches)
+		}
+	}
+}
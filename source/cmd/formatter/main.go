@@ -0,0 +1,424 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/config"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/diff"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/format"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lsp"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Process exit codes.
+const (
+	ExitCodeOk           = 0 // All files are formatted.
+	ExitCodeNotFormatted = 1 // Some files need formatting (in check mode).
+	ExitCodeError        = 2 // Failed to read, lex or parse some files.
+)
+
+type CommandLineOptions struct {
+	write_in_place  bool
+	check           bool
+	diff            bool
+	diff_options    diff.UnifiedDiffOptions
+	extensions      string
+	exclude         []string
+	num_jobs        int
+	assume_filename string
+	config_file     string
+	config_loader   *config.Loader
+	style           string
+	overrides       []OptionOverride
+	print_config    bool
+	line_ranges     []format.LineRange
+	changed_since   string
+	no_verify       bool
+	// Format result once more and print difference instead of formatting.
+	verify_idempotent bool
+	// Changed lines from diff in stdin, by absolute file names.
+	stdin_changed_lines map[string][]format.LineRange
+}
+
+// Special file name for reading from stdin and writing to stdout.
+const StdinFileName = "-"
+
+// Result of processing of single file.
+type FileResult struct {
+	output       string // Text to print into stdout.
+	is_formatted bool   // True if file contents is already formatted.
+	err          error
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "lsp" {
+		os.Exit(RunLanguageServer(os.Args[2:]))
+	}
+
+	options := CommandLineOptions{}
+	flag.BoolVar(&options.write_in_place, "w", false, "write result to source file instead of stdout, if it differs from the file contents")
+	flag.BoolVar(&options.check, "check", false, "do not print formatted text, list files whose formatting differs instead")
+	flag.BoolVar(&options.check, "l", false, "same as -check")
+	flag.BoolVar(&options.diff, "diff", false, "print unified diff of formatting changes instead of formatted text")
+	flag.BoolVar(&options.diff, "d", false, "same as -diff")
+	flag.UintVar(&options.diff_options.ContextLines, "diff-context", 3, "number of context lines in diff")
+	flag.BoolVar(&options.diff_options.Colored, "color", false, "highlight diff using terminal colors")
+	flag.StringVar(&options.extensions, "ext", ".u,.uh", "comma-separated list of extensions of files to format in directories")
+	flag.Func("exclude", "exclude files and directories matching given gitignore-like pattern (may be repeated)", func(s string) error {
+		options.exclude = append(options.exclude, s)
+		return nil
+	})
+	flag.IntVar(&options.num_jobs, "j", runtime.GOMAXPROCS(0), "number of files to process in parallel")
+	flag.StringVar(&options.assume_filename, "assume-filename", "", "file name to use in messages when reading from stdin")
+	flag.StringVar(&options.config_file, "config", "", "use given configuration file instead of searching for "+config.ConfigFileName+" files")
+	flag.BoolVar(&options.print_config, "print-config", false, "print resolved formatting options for given files instead of formatting them")
+	flag.StringVar(&options.style, "style", "", "style preset (\"default\", \"ustlib\", \"compact\", \"wide\"), overrides style of configuration files")
+	flag.Func("lines", "format only lines in range \"START:END\" (may be repeated)", func(s string) error {
+		line_range, err := ParseLineRange(s)
+		if err != nil {
+			return err
+		}
+		options.line_ranges = append(options.line_ranges, line_range)
+		return nil
+	})
+	flag.StringVar(&options.changed_since, "changed-since", "", "format only lines changed since given git revision, or lines added in unified diff from stdin if \"-\" is specified (paths in the diff are relative to git repository root or current directory outside repositories)")
+	RegisterOptionFlags(&options.overrides)
+	flag.BoolVar(&options.no_verify, "no-verify", false, "do not check that formatting preserves source lexems")
+	flag.BoolVar(&options.verify_idempotent, "verify-idempotent", false, "format result once more and print diff for files where second pass changes formatting")
+	flag.Parse()
+
+	if options.changed_since != "" && len(options.line_ranges) > 0 {
+		fmt.Fprintln(os.Stderr, "-changed-since and -lines can not be used together")
+		os.Exit(ExitCodeError)
+	}
+
+	if options.verify_idempotent &&
+		(options.write_in_place || options.check || options.diff || options.changed_since != "" || len(options.line_ranges) > 0) {
+		fmt.Fprintln(os.Stderr, "-verify-idempotent can not be used together with -w, -check, -diff, -lines or -changed-since")
+		os.Exit(ExitCodeError)
+	}
+
+	options.config_loader = config.NewLoader(options.config_file)
+
+	if options.print_config {
+		os.Exit(PrintConfig(&options))
+	}
+
+	has_errors := false
+	has_not_formatted_files := false
+
+	files_collector := NewFilesCollector(strings.Split(options.extensions, ","), options.exclude)
+	for _, path := range flag.Args() {
+		files_collector.AddPath(path)
+	}
+
+	if options.changed_since == ChangedLinesFromStdin {
+		diff_text, err := ReadAll(os.Stdin)
+		if err != nil {
+			ReportError(StdinFileName, err)
+			os.Exit(ExitCodeError)
+		}
+
+		// Paths in diffs produced by git are relative to the repository root.
+		// Outside git repositories resolve them against current directory.
+		current_dir, err := os.Getwd()
+		if err != nil {
+			ReportError(StdinFileName, err)
+			os.Exit(ExitCodeError)
+		}
+		base_dir, err := GetGitTopLevelDirectory(current_dir)
+		if err != nil {
+			base_dir = current_dir
+		}
+		options.stdin_changed_lines = ResolveDiffFileNames(ParseDiffChangedLines(diff_text), base_dir)
+
+		if len(flag.Args()) == 0 {
+			// Format files mentioned in the diff.
+			file_names := make([]string, 0)
+			for file_name := range options.stdin_changed_lines {
+				if files_collector.hasSourceExtension(file_name) {
+					// Use paths relative to current directory, if possible.
+					rel_file_name, err := filepath.Rel(current_dir, file_name)
+					if err == nil {
+						file_name = rel_file_name
+					}
+					file_names = append(file_names, file_name)
+				}
+			}
+			sort.Strings(file_names)
+			for _, file_name := range file_names {
+				files_collector.AddPath(file_name)
+			}
+		}
+	} else if len(flag.Args()) == 0 {
+		files_collector.AddPath(StdinFileName)
+	}
+
+	for _, file_error := range files_collector.errors {
+		ReportError(file_error.file_name, file_error.err)
+		has_errors = true
+	}
+
+	ProcessFilesInParallel(
+		files_collector.files,
+		options.num_jobs,
+		func(file_name string) FileResult {
+			return FormatFile(file_name, &options)
+		},
+		func(file_name string, result *FileResult) {
+			fmt.Print(result.output)
+			if result.err != nil {
+				ReportError(GetDisplayFileName(file_name, &options), result.err)
+				has_errors = true
+			} else if !result.is_formatted {
+				has_not_formatted_files = true
+			}
+		})
+
+	if has_errors {
+		os.Exit(ExitCodeError)
+	}
+	if (options.check || options.verify_idempotent) && has_not_formatted_files {
+		os.Exit(ExitCodeNotFormatted)
+	}
+	os.Exit(ExitCodeOk)
+}
+
+// Format file and write result or produce output text, depending on options.
+// May be called in parallel for different files.
+func FormatFile(file_name string, options *CommandLineOptions) FileResult {
+
+	if file_name == StdinFileName && options.write_in_place {
+		return FileResult{err: errors.New("can not write result in place when reading from stdin")}
+	}
+
+	var file_contents string
+	var err error
+	if file_name == StdinFileName {
+		file_contents, err = ReadAll(os.Stdin)
+	} else {
+		file_contents, err = ReadFile(file_name)
+	}
+	if err != nil {
+		return FileResult{err: err}
+	}
+
+	display_file_name := GetDisplayFileName(file_name, options)
+
+	formatting_options, err := GetFormattingOptions(file_name, options)
+	if err != nil {
+		return FileResult{err: err}
+	}
+
+	line_ranges, err := GetLineRanges(file_name, options)
+	if err != nil {
+		return FileResult{err: err}
+	}
+
+	text_formatted, err := format.SourceLines([]byte(file_contents), formatting_options, line_ranges)
+	if err != nil {
+		return FileResult{err: err}
+	}
+
+	if !options.no_verify {
+		// Safety net against formatter bugs - never produce code with different meaning.
+		lexems, err := lexer.SplitProgramIntoLexems(file_contents)
+		if err != nil {
+			return FileResult{err: err}
+		}
+		err = format.VerifyLexemsPreserved(lexems, string(text_formatted), &formatting_options)
+		if err != nil {
+			return FileResult{err: err}
+		}
+	}
+
+	if options.verify_idempotent {
+		diff_text, err := format.VerifyIdempotent(display_file_name, text_formatted, formatting_options, &options.diff_options)
+		// Treat non-idempotent files as not formatted.
+		return FileResult{output: diff_text, is_formatted: diff_text == "", err: err}
+	}
+
+	result := FileResult{is_formatted: string(text_formatted) == file_contents}
+
+	if options.check {
+		if !result.is_formatted {
+			result.output = display_file_name + "\n"
+		}
+	} else if options.diff {
+		result.output = diff.Unified(display_file_name+".orig", display_file_name, file_contents, string(text_formatted), &options.diff_options)
+	}
+
+	if options.write_in_place {
+		if !result.is_formatted {
+			result.err = WriteFileAtomically(file_name, text_formatted)
+		}
+	} else if !options.check && !options.diff {
+		result.output = string(text_formatted)
+	}
+
+	return result
+}
+
+// Run language server, communicating via stdin and stdout. Returns exit code.
+func RunLanguageServer(args []string) int {
+	flag_set := flag.NewFlagSet("lsp", flag.ExitOnError)
+	config_file := flag_set.String("config", "", "use given configuration file instead of searching for "+config.ConfigFileName+" files")
+	flag_set.Parse(args)
+
+	config_loader := config.NewLoader(*config_file)
+	get_options := func(file_name string) (format.Options, error) {
+		if file_name == "" {
+			return format.GetDefaultOptions(), nil
+		}
+		return config_loader.GetOptionsForFile(file_name, "")
+	}
+
+	err := lsp.NewServer(os.Stdin, os.Stdout, get_options).Run()
+	if err == lsp.ErrExitWithoutShutdown {
+		// Exit code required by the protocol.
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return ExitCodeError
+	}
+	return ExitCodeOk
+}
+
+// Get options from configuration files, overridden by options from command line.
+func GetFormattingOptions(file_name string, options *CommandLineOptions) (format.Options, error) {
+
+	// For stdin search configuration files starting from current directory, if file name is not specified.
+	config_search_file_name := file_name
+	if file_name == StdinFileName {
+		config_search_file_name = options.assume_filename
+		if config_search_file_name == "" {
+			config_search_file_name = StdinFileName
+		}
+	}
+
+	formatting_options, err := options.config_loader.GetOptionsForFile(config_search_file_name, options.style)
+	if err != nil {
+		return formatting_options, err
+	}
+
+	err = ApplyOptionOverrides(&formatting_options, options.overrides)
+	return formatting_options, err
+}
+
+// Print resolved options for each file from command line. Returns exit code.
+func PrintConfig(options *CommandLineOptions) int {
+	file_names := flag.Args()
+	if len(file_names) == 0 {
+		file_names = []string{StdinFileName}
+	}
+
+	exit_code := ExitCodeOk
+	for _, file_name := range file_names {
+		formatting_options, err := GetFormattingOptions(file_name, options)
+		if err != nil {
+			ReportError(GetDisplayFileName(file_name, options), err)
+			exit_code = ExitCodeError
+			continue
+		}
+
+		if len(file_names) > 1 {
+			fmt.Printf("# %s\n", GetDisplayFileName(file_name, options))
+		}
+		fmt.Print(config.SerializeOptions(&formatting_options))
+	}
+
+	return exit_code
+}
+
+// Get ranges of lines to format. Returns nil if whole file should be formatted.
+func GetLineRanges(file_name string, options *CommandLineOptions) ([]format.LineRange, error) {
+
+	if options.changed_since == "" {
+		return options.line_ranges, nil
+	}
+
+	if file_name == StdinFileName {
+		if options.changed_since == ChangedLinesFromStdin {
+			return nil, errors.New("can not read both diff and source from stdin")
+		}
+		if options.assume_filename == "" {
+			return nil, errors.New("file name should be specified via -assume-filename to get changed lines")
+		}
+		file_name = options.assume_filename
+	}
+
+	if options.changed_since == ChangedLinesFromStdin {
+		abs_file_name, err := filepath.Abs(file_name)
+		if err != nil {
+			return nil, err
+		}
+		// Files not mentioned in the diff have no changed lines.
+		return append([]format.LineRange{}, options.stdin_changed_lines[abs_file_name]...), nil
+	}
+
+	return GetGitChangedLines(file_name, options.changed_since)
+}
+
+// Parse range like "10:20".
+func ParseLineRange(s string) (format.LineRange, error) {
+	first_str, last_str, ok := strings.Cut(s, ":")
+	if ok {
+		first, first_err := strconv.ParseUint(first_str, 10, 32)
+		last, last_err := strconv.ParseUint(last_str, 10, 32)
+		if first_err == nil && last_err == nil && first >= 1 && first <= last {
+			return format.LineRange{First: uint(first), Last: uint(last)}, nil
+		}
+	}
+
+	return format.LineRange{}, errors.New("expected \"START:END\" with 1 <= START <= END")
+}
+
+// Get file name for messages. For stdin file name specified in options is used.
+func GetDisplayFileName(file_name string, options *CommandLineOptions) string {
+	if file_name == StdinFileName {
+		if options.assume_filename != "" {
+			return options.assume_filename
+		}
+		return "<stdin>"
+	}
+	return file_name
+}
+
+// Print error in format "file:line:column: message" for errors with source position or "file: message" for other errors.
+func ReportError(file_name string, err error) {
+	var src_error *lexer.SrcError
+	if errors.As(err, &src_error) {
+		fmt.Fprintf(os.Stderr, "%s:%s\n", file_name, err.Error())
+	} else {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", file_name, err.Error())
+	}
+}
+
+func ReadFile(s string) (string, error) {
+	file, e := os.Open(s)
+	if e != nil {
+		return "", e
+	}
+	defer file.Close()
+
+	return ReadAll(file)
+}
+
+// Read until end of file, handling partial reads.
+func ReadAll(reader io.Reader) (string, error) {
+	bytes, e := io.ReadAll(reader)
+	if e != nil {
+		return "", e
+	}
+
+	return string(bytes), nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/format"
+	"strconv"
+	"strings"
+)
+
+// Formatting option, specified in command line.
+type OptionOverride struct {
+	descriptor *format.OptionDescriptor
+	value      string
+}
+
+// Register command line flag for each formatting option.
+// Flag names are option names with "-" instead of "_".
+// Also register shortcut flags "-indent" and "-line-end".
+func RegisterOptionFlags(overrides *[]OptionOverride) {
+
+	for i := range format.OptionDescriptors {
+		descriptor := &format.OptionDescriptors[i]
+
+		flag.Func(strings.ReplaceAll(descriptor.Name, "_", "-"), descriptor.Description, func(value string) error {
+			if descriptor.Kind == format.OptionKindString && strings.Contains(value, "\\") {
+				// Allow escape sequences like "\t".
+				unquoted, err := strconv.Unquote("\"" + value + "\"")
+				if err != nil {
+					return fmt.Errorf("invalid escape sequence in \"%s\"", value)
+				}
+				value = unquoted
+			}
+			return addOptionOverride(overrides, descriptor, value)
+		})
+	}
+
+	flag.Func("indent", "indentation: \"tab\" or \"spaces:N\"", func(value string) error {
+		indentation_sequence, err := ParseIndentationSpec(value)
+		if err != nil {
+			return err
+		}
+		return addOptionOverride(overrides, format.FindOptionDescriptor("indentation_sequence"), indentation_sequence)
+	})
+
+	flag.Func("line-end", "line ending: \"lf\", \"crlf\" or \"cr\"", func(value string) error {
+		line_end_sequence, err := ParseLineEndSpec(value)
+		if err != nil {
+			return err
+		}
+		return addOptionOverride(overrides, format.FindOptionDescriptor("line_end_sequence"), line_end_sequence)
+	})
+}
+
+// Check option value and add it to overrides list.
+func addOptionOverride(overrides *[]OptionOverride, descriptor *format.OptionDescriptor, value string) error {
+	options := format.GetDefaultOptions()
+	err := descriptor.Set(&options, value)
+	if err != nil {
+		return err
+	}
+
+	*overrides = append(*overrides, OptionOverride{descriptor: descriptor, value: value})
+	return nil
+}
+
+// Apply options from command line in order of their specification.
+func ApplyOptionOverrides(options *format.Options, overrides []OptionOverride) error {
+	for _, override := range overrides {
+		err := override.descriptor.Set(options, override.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ParseIndentationSpec(spec string) (string, error) {
+	if spec == "tab" || spec == "tabs" {
+		return "\t", nil
+	}
+
+	if count_str, ok := strings.CutPrefix(spec, "spaces:"); ok {
+		count, err := strconv.ParseUint(count_str, 10, 8)
+		if err == nil && count > 0 {
+			return strings.Repeat(" ", int(count)), nil
+		}
+	}
+
+	return "", errors.New("expected \"tab\" or \"spaces:N\"")
+}
+
+func ParseLineEndSpec(spec string) (string, error) {
+	switch spec {
+	case "lf":
+		return "\n", nil
+	case "crlf":
+		return "\r\n", nil
+	case "cr":
+		return "\r", nil
+	}
+
+	return "", errors.New("expected \"lf\", \"crlf\" or \"cr\"")
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"sync"
+)
+
+// Process files using given number of parallel workers.
+// Results are passed into the consumer function in order of files, regardless of processing completion order.
+func ProcessFilesInParallel(
+	files []string,
+	num_workers int,
+	process func(file_name string) FileResult,
+	consume func(file_name string, result *FileResult)) {
+
+	results := make([]FileResult, len(files))
+	results_ready := make([]chan struct{}, len(files))
+	for i := range results_ready {
+		results_ready[i] = make(chan struct{})
+	}
+
+	jobs := make(chan int)
+	wait_group := sync.WaitGroup{}
+
+	for i := 0; i < max(num_workers, 1); i++ {
+		wait_group.Add(1)
+		go func() {
+			defer wait_group.Done()
+			for file_index := range jobs {
+				results[file_index] = process(files[file_index])
+				close(results_ready[file_index])
+			}
+		}()
+	}
+
+	go func() {
+		for i := range files {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	for i := range files {
+		<-results_ready[i]
+		consume(files[i], &results[i])
+	}
+
+	wait_group.Wait()
+}
@@ -0,0 +1,398 @@
+package config
+
+import (
+	"fmt"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/format"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Name of configuration file, which is searched in directory of formatted file and all its parent directories.
+const ConfigFileName = ".uformat"
+
+// Key for selection of style preset, which is applied before all other options of the configuration file.
+const StyleKey = "style"
+
+// Parsed configuration file.
+// Syntax is a subset of TOML - only "key = value" pairs with string or integer values are supported.
+type ConfigFile struct {
+	file_name  string
+	style      string
+	style_line uint
+	entries    []ConfigEntry
+}
+
+type ConfigEntry struct {
+	key   string
+	value string // Unescaped value of string or digits of integer.
+	line  uint
+}
+
+// Error in configuration file.
+type ConfigError struct {
+	file_name string
+	line      uint
+	text      string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.file_name, e.line, e.text)
+}
+
+func ReadConfigFile(file_name string) (*ConfigFile, error) {
+	bytes, err := os.ReadFile(file_name)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseConfigFile(file_name, string(bytes))
+}
+
+// Parse config file contents. All keys and values are validated.
+func ParseConfigFile(file_name string, text string) (*ConfigFile, error) {
+
+	result := &ConfigFile{file_name: file_name}
+
+	for line_index, line := range strings.Split(text, "\n") {
+		line_number := uint(line_index + 1)
+
+		new_error := func(format string, args ...any) error {
+			return &ConfigError{file_name: file_name, line: line_number, text: fmt.Sprintf(format, args...)}
+		}
+
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			return nil, new_error("tables are not supported")
+		}
+
+		assignment_pos := strings.IndexByte(line, '=')
+		if assignment_pos < 0 {
+			return nil, new_error("expected \"key = value\"")
+		}
+
+		key := strings.TrimSpace(line[:assignment_pos])
+		if !isValidKey(key) {
+			return nil, new_error("invalid key \"%s\"", key)
+		}
+
+		value, value_kind, err := parseValue(strings.TrimSpace(line[assignment_pos+1:]))
+		if err != nil {
+			return nil, new_error("%s", err.Error())
+		}
+
+		if key == StyleKey {
+			if result.style_line != 0 {
+				return nil, new_error("duplicate key \"%s\", previous definition is at line %d", key, result.style_line)
+			}
+			if value_kind != format.OptionKindString {
+				return nil, new_error("value of \"%s\" should be a string", key)
+			}
+			_, err := format.GetStyleOptions(value)
+			if err != nil {
+				return nil, new_error("%s", err.Error())
+			}
+			result.style = value
+			result.style_line = line_number
+			continue
+		}
+
+		descriptor := format.FindOptionDescriptor(key)
+		if descriptor == nil {
+			return nil, new_error("unknown key \"%s\"", key)
+		}
+
+		for _, entry := range result.entries {
+			if entry.key == key {
+				return nil, new_error("duplicate key \"%s\", previous definition is at line %d", key, entry.line)
+			}
+		}
+
+		if value_kind != descriptor.Kind {
+			if descriptor.Kind == format.OptionKindString {
+				return nil, new_error("value of \"%s\" should be a string", key)
+			} else {
+				return nil, new_error("value of \"%s\" should be an integer", key)
+			}
+		}
+
+		// Check value, applying it to temporary options.
+		options := format.GetDefaultOptions()
+		err = descriptor.Set(&options, value)
+		if err != nil {
+			return nil, new_error("invalid value of \"%s\": %s", key, err.Error())
+		}
+
+		result.entries = append(result.entries, ConfigEntry{key: key, value: value, line: line_number})
+	}
+
+	return result, nil
+}
+
+// Set all options specified in this config file.
+// If style is specified, all options are reset to the style options first.
+func (config_file *ConfigFile) Apply(options *format.Options) error {
+	if config_file.style != "" {
+		style_options, err := config_file.getStyleOptions()
+		if err != nil {
+			return err
+		}
+		*options = style_options
+	}
+
+	return config_file.applyEntries(options)
+}
+
+func (config_file *ConfigFile) getStyleOptions() (format.Options, error) {
+	style_options, err := format.GetStyleOptions(config_file.style)
+	if err != nil {
+		return style_options, &ConfigError{file_name: config_file.file_name, line: config_file.style_line, text: err.Error()}
+	}
+	return style_options, nil
+}
+
+// Set options explicitly specified in this config file, ignoring style.
+func (config_file *ConfigFile) applyEntries(options *format.Options) error {
+	for _, entry := range config_file.entries {
+		err := format.FindOptionDescriptor(entry.key).Set(options, entry.value)
+		if err != nil {
+			return &ConfigError{file_name: config_file.file_name, line: entry.line, text: err.Error()}
+		}
+	}
+	return nil
+}
+
+func isValidKey(key string) bool {
+	if len(key) == 0 {
+		return false
+	}
+	for _, c := range key {
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse value and trailing comment after it.
+func parseValue(s string) (string, format.OptionKind, error) {
+
+	value := ""
+	kind := format.OptionKindString
+
+	if strings.HasPrefix(s, "\"") {
+		// Basic string with escape sequences.
+		builder := strings.Builder{}
+		s = s[1:]
+		for {
+			if len(s) == 0 {
+				return "", kind, fmt.Errorf("unterminated string")
+			}
+			if s[0] == '"' {
+				s = s[1:]
+				break
+			}
+			if s[0] != '\\' {
+				builder.WriteByte(s[0])
+				s = s[1:]
+				continue
+			}
+			if len(s) < 2 {
+				return "", kind, fmt.Errorf("unterminated string")
+			}
+			switch s[1] {
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			case 't':
+				builder.WriteByte('\t')
+			case '\\':
+				builder.WriteByte('\\')
+			case '"':
+				builder.WriteByte('"')
+			default:
+				return "", kind, fmt.Errorf("invalid escape sequence \"\\%c\"", s[1])
+			}
+			s = s[2:]
+		}
+		value = builder.String()
+
+	} else if strings.HasPrefix(s, "'") {
+		// Literal string without escape sequences.
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", kind, fmt.Errorf("unterminated string")
+		}
+		value = s[1 : end+1]
+		s = s[end+2:]
+
+	} else {
+		// Integer.
+		end := 0
+		for end < len(s) && ((s[end] >= '0' && s[end] <= '9') || s[end] == '_' || s[end] == '+') {
+			end++
+		}
+		if end == 0 {
+			return "", kind, fmt.Errorf("expected string or integer value")
+		}
+		digits := strings.ReplaceAll(strings.TrimPrefix(s[:end], "+"), "_", "")
+		_, err := strconv.ParseUint(digits, 10, 64)
+		if err != nil {
+			return "", kind, fmt.Errorf("invalid integer \"%s\"", s[:end])
+		}
+		value = digits
+		kind = format.OptionKindUint
+		s = s[end:]
+	}
+
+	s = strings.TrimSpace(s)
+	if len(s) > 0 && s[0] != '#' {
+		return "", kind, fmt.Errorf("unexpected text after value: \"%s\"", s)
+	}
+
+	return value, kind, nil
+}
+
+// Loads and caches configuration files. May be used from multiple goroutines.
+type Loader struct {
+	explicit_config_file string // If not empty, configuration files are not searched.
+	mutex                sync.Mutex
+	config_files         map[string]*ConfigFile // Nil for non-existing files.
+}
+
+// If explicit config file is not empty, it is used for all files.
+func NewLoader(explicit_config_file string) *Loader {
+	return &Loader{explicit_config_file: explicit_config_file, config_files: make(map[string]*ConfigFile)}
+}
+
+// Get formatting options for given source file.
+// Configuration files from directory of the file and all its parent directories are applied,
+// starting from the farthest one, so that nearer configuration files override options.
+// Style preset is selected by the nearest configuration file, which specifies it,
+// or by given style, if it is not empty. Options of the preset are applied first,
+// then options explicitly specified in all configuration files.
+func (loader *Loader) GetOptionsForFile(file_name string, style string) (format.Options, error) {
+
+	options := format.GetDefaultOptions()
+
+	config_files, err := loader.getConfigFilesForFile(file_name)
+	if err != nil {
+		return options, err
+	}
+
+	if style != "" {
+		options, err = format.GetStyleOptions(style)
+		if err != nil {
+			return options, err
+		}
+	} else {
+		for _, config_file := range config_files {
+			if config_file.style != "" {
+				options, err = config_file.getStyleOptions()
+				if err != nil {
+					return options, err
+				}
+				break
+			}
+		}
+	}
+
+	for i := len(config_files) - 1; i >= 0; i-- {
+		err := config_files[i].applyEntries(&options)
+		if err != nil {
+			return options, err
+		}
+	}
+
+	return options, nil
+}
+
+// Returns configuration files for given source file, starting from the nearest one.
+func (loader *Loader) getConfigFilesForFile(file_name string) ([]*ConfigFile, error) {
+
+	if loader.explicit_config_file != "" {
+		config_file, err := loader.getConfigFile(loader.explicit_config_file, true)
+		if err != nil {
+			return nil, err
+		}
+		return []*ConfigFile{config_file}, nil
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(file_name))
+	if err != nil {
+		return nil, err
+	}
+
+	config_files := make([]*ConfigFile, 0)
+	for {
+		config_file, err := loader.getConfigFile(filepath.Join(dir, ConfigFileName), false)
+		if err != nil {
+			return nil, err
+		}
+		if config_file != nil {
+			config_files = append(config_files, config_file)
+		}
+
+		parent_dir := filepath.Dir(dir)
+		if parent_dir == dir {
+			break
+		}
+		dir = parent_dir
+	}
+
+	return config_files, nil
+}
+
+// Returns nil if file doesn't exist and it isn't required.
+func (loader *Loader) getConfigFile(file_name string, required bool) (*ConfigFile, error) {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	config_file, ok := loader.config_files[file_name]
+	if ok {
+		return config_file, nil
+	}
+
+	stat, err := os.Stat(file_name)
+	if err != nil || stat.IsDir() {
+		if required {
+			if err == nil {
+				err = fmt.Errorf("%s is a directory", file_name)
+			}
+			return nil, err
+		}
+		loader.config_files[file_name] = nil
+		return nil, nil
+	}
+
+	config_file, err = ReadConfigFile(file_name)
+	if err != nil {
+		return nil, err
+	}
+
+	loader.config_files[file_name] = config_file
+	return config_file, nil
+}
+
+// Convert options into configuration file text.
+func SerializeOptions(options *format.Options) string {
+	builder := strings.Builder{}
+	for _, descriptor := range format.OptionDescriptors {
+		value := descriptor.Get(options)
+		if descriptor.Kind == format.OptionKindString {
+			value = strconv.Quote(value)
+		}
+		builder.WriteString(descriptor.Name)
+		builder.WriteString(" = ")
+		builder.WriteString(value)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/format"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseConfigFile(t *testing.T) {
+
+	with := func(modify func(options *format.Options)) format.Options {
+		options := format.GetDefaultOptions()
+		modify(&options)
+		return options
+	}
+
+	test_cases := []struct {
+		text     string
+		expected format.Options
+	}{
+		{"", format.GetDefaultOptions()},
+		{"# Comment only\n\n", format.GetDefaultOptions()},
+		{"max_line_width = 100", with(func(o *format.Options) { o.MaxLineWidth = 100 })},
+		{"max_line_width = 1_000 # Comment", with(func(o *format.Options) { o.MaxLineWidth = 1000 })},
+		{"  tab_size=+2  ", with(func(o *format.Options) { o.TabSize = 2 })},
+		{"max_empty_lines = 0", with(func(o *format.Options) { o.MaxEmptyLines = 0 })},
+		{`indentation_sequence = "  "`, with(func(o *format.Options) { o.IndentationSequence = "  " })},
+		{`indentation_sequence = '    '`, with(func(o *format.Options) { o.IndentationSequence = "    " })},
+		{`line_end_sequence = "\r\n"`, with(func(o *format.Options) { o.LineEndSequence = "\r\n" })},
+		{`number_style = "upper"`, with(func(o *format.Options) { o.NumberStyle = format.NumberStyleUpper })},
+		{"style = \"wide\"", format.StylePresets["wide"]},
+		{"max_line_width = 90\nstyle = \"compact\"", with(func(o *format.Options) {
+			*o = format.StylePresets["compact"]
+			o.MaxLineWidth = 90
+		})},
+	}
+
+	for _, test_case := range test_cases {
+		config_file, err := ParseConfigFile("test", test_case.text)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test_case.text, err)
+			continue
+		}
+
+		options := format.GetDefaultOptions()
+		err = config_file.Apply(&options)
+		if err != nil {
+			t.Errorf("%q: unexpected error on apply: %s", test_case.text, err)
+		} else if options != test_case.expected {
+			t.Errorf("%q: got options %+v, expected %+v", test_case.text, options, test_case.expected)
+		}
+	}
+}
+
+func TestParseConfigFileErrors(t *testing.T) {
+	test_cases := []struct {
+		text  string
+		error string
+	}{
+		{"[table]", "test:1: tables are not supported"},
+		{"max_line_width", "test:1: expected \"key = value\""},
+		{"bad key = 1", "test:1: invalid key \"bad key\""},
+		{"unknown_option = 1", "test:1: unknown key \"unknown_option\""},
+		{"\nmax_line_width = 80\nmax_line_width = 90", "test:3: duplicate key \"max_line_width\", previous definition is at line 2"},
+		{"style = \"wide\"\nstyle = \"compact\"", "test:2: duplicate key \"style\", previous definition is at line 1"},
+		{"style = 1", "test:1: value of \"style\" should be a string"},
+		{"style = \"unknown\"", "test:1: unknown style \"unknown\", expected one of: compact, default, ustlib, wide"},
+		{"max_line_width = \"80\"", "test:1: value of \"max_line_width\" should be an integer"},
+		{"indentation_sequence = 4", "test:1: value of \"indentation_sequence\" should be a string"},
+		{"max_line_width = 0", "test:1: invalid value of \"max_line_width\": invalid positive integer \"0\""},
+		{"indentation_sequence = '\\t'", "test:1: invalid value of \"indentation_sequence\": indentation sequence should be non-empty and contain only spaces and tabs"},
+		{"number_style = \"mixed\"", "test:1: invalid value of \"number_style\": number style should be \"preserve\", \"lower\" or \"upper\""},
+		{"max_line_width = 80 90", "test:1: unexpected text after value: \"90\""},
+		{"indentation_sequence = \"\\t", "test:1: unterminated string"},
+		{"indentation_sequence = \"\\q\"", "test:1: invalid escape sequence \"\\q\""},
+		{"max_line_width = abc", "test:1: expected string or integer value"},
+	}
+
+	for _, test_case := range test_cases {
+		_, err := ParseConfigFile("test", test_case.text)
+		if err == nil {
+			t.Errorf("%q: expected error", test_case.text)
+		} else if err.Error() != test_case.error {
+			t.Errorf("%q: error is %q, expected %q", test_case.text, err.Error(), test_case.error)
+		}
+	}
+}
+
+func TestLoaderOptionsPrecedence(t *testing.T) {
+
+	with := func(style string, modify func(options *format.Options)) format.Options {
+		options := format.StylePresets[style]
+		modify(&options)
+		return options
+	}
+
+	test_cases := []struct {
+		outer_config string
+		inner_config string
+		style        string // Style from command line.
+		expected     format.Options
+	}{
+		{
+			"max_line_width = 90",
+			"tab_size = 2",
+			"",
+			with("default", func(o *format.Options) { o.MaxLineWidth = 90; o.TabSize = 2 }),
+		},
+		{
+			// Nearer file overrides options of farther file.
+			"max_line_width = 90",
+			"max_line_width = 100",
+			"",
+			with("default", func(o *format.Options) { o.MaxLineWidth = 100 }),
+		},
+		{
+			// Style of nearer file doesn't reset options of farther file.
+			"line_end_sequence = \"\\r\\n\"",
+			"style = \"wide\"",
+			"",
+			with("wide", func(o *format.Options) { o.LineEndSequence = "\r\n" }),
+		},
+		{
+			// Style of nearer file is used.
+			"style = \"compact\"\nmax_line_width = 90",
+			"style = \"wide\"",
+			"",
+			with("wide", func(o *format.Options) { o.MaxLineWidth = 90 }),
+		},
+		{
+			"style = \"compact\"",
+			"tab_size = 2",
+			"",
+			with("compact", func(o *format.Options) { o.TabSize = 2 }),
+		},
+		{
+			// Style from command line replaces only the style preset.
+			"style = \"compact\"\nline_end_sequence = \"\\r\\n\"",
+			"max_line_width = 90",
+			"wide",
+			with("wide", func(o *format.Options) { o.LineEndSequence = "\r\n"; o.MaxLineWidth = 90 }),
+		},
+	}
+
+	for _, test_case := range test_cases {
+		outer_dir := t.TempDir()
+		inner_dir := filepath.Join(outer_dir, "inner")
+		if err := os.Mkdir(inner_dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(outer_dir, ConfigFileName), []byte(test_case.outer_config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(inner_dir, ConfigFileName), []byte(test_case.inner_config), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		options, err := NewLoader("").GetOptionsForFile(filepath.Join(inner_dir, "test.u"), test_case.style)
+		if err != nil {
+			t.Errorf("%q, %q: unexpected error: %s", test_case.outer_config, test_case.inner_config, err)
+		} else if options != test_case.expected {
+			t.Errorf("%q, %q: got options %+v, expected %+v", test_case.outer_config, test_case.inner_config, options, test_case.expected)
+		}
+	}
+}
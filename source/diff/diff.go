@@ -0,0 +1,258 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type EditType byte
+
+const (
+	EditTypeEqual EditType = iota
+	EditTypeDelete
+	EditTypeInsert
+)
+
+// Single line edit.
+type Edit struct {
+	Type     EditType
+	OldIndex int // Index of line in old lines list. Valid for equal and delete edits.
+	NewIndex int // Index of line in new lines list. Valid for equal and insert edits.
+}
+
+// Calculate shortest edit script for two lists of lines, using Myers algorithm.
+// See "An O(ND) Difference Algorithm and Its Variations", Eugene W. Myers.
+func CalculateEditScript(old_lines []string, new_lines []string) []Edit {
+
+	n := len(old_lines)
+	m := len(new_lines)
+	max_d := n + m
+	offset := max_d + 1
+
+	// v[offset + k] contains furthest reaching x for diagonal k.
+	v := make([]int, 2*max_d+3)
+
+	// Save relevant part of "v" for each step, in order to restore path later.
+	trace := make([][]int, 0)
+
+	for d := 0; d <= max_d; d++ {
+
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+
+		for k := -d; k <= d; k += 2 {
+			x := 0
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Move down.
+			} else {
+				x = v[offset+k-1] + 1 // Move right.
+			}
+			y := x - k
+
+			// Follow diagonal.
+			for x < n && y < m && old_lines[x] == new_lines[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrackEditScript(trace, n, m)
+			}
+		}
+	}
+
+	panic("Unexpected end of edit script calculation!")
+}
+
+func backtrackEditScript(trace [][]int, n int, m int) []Edit {
+
+	result := make([]Edit, 0)
+
+	x := n
+	y := m
+	for d := len(trace) - 1; d >= 0; d-- {
+		// Stored part of "v" starts with diagonal -d.
+		v_at := func(k int) int { return trace[d][k+d] }
+
+		k := x - y
+
+		prev_k := 0
+		if k == -d || (k != d && v_at(k-1) < v_at(k+1)) {
+			prev_k = k + 1
+		} else {
+			prev_k = k - 1
+		}
+
+		prev_x := 0
+		if d > 0 {
+			prev_x = v_at(prev_k)
+		}
+		prev_y := prev_x - prev_k
+
+		for x > prev_x && y > prev_y && x > 0 && y > 0 {
+			x--
+			y--
+			result = append(result, Edit{Type: EditTypeEqual, OldIndex: x, NewIndex: y})
+		}
+
+		if d > 0 {
+			if x == prev_x {
+				result = append(result, Edit{Type: EditTypeInsert, OldIndex: x, NewIndex: prev_y})
+			} else {
+				result = append(result, Edit{Type: EditTypeDelete, OldIndex: prev_x, NewIndex: y})
+			}
+		}
+
+		x = prev_x
+		y = prev_y
+	}
+
+	// Edits were collected in reverse order.
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return result
+}
+
+type UnifiedDiffOptions struct {
+	ContextLines uint
+	Colored      bool // Use terminal escape sequences to highlight diff.
+}
+
+const (
+	colorReset           = "\x1b[0m"
+	colorBold            = "\x1b[1m"
+	colorRed             = "\x1b[31m"
+	colorGreen           = "\x1b[32m"
+	colorCyan            = "\x1b[36m"
+	noNewlineAtEndOfFile = "\\ No newline at end of file\n"
+)
+
+// Produce unified diff of two texts.
+// Returns empty string if texts are equal.
+func Unified(old_name string, new_name string, old_text string, new_text string, options *UnifiedDiffOptions) string {
+
+	if old_text == new_text {
+		return ""
+	}
+
+	old_lines := SplitLines(old_text)
+	new_lines := SplitLines(new_text)
+
+	edits := CalculateEditScript(old_lines, new_lines)
+
+	builder := strings.Builder{}
+
+	writeColored(&builder, "--- "+old_name+"\n", colorBold, options)
+	writeColored(&builder, "+++ "+new_name+"\n", colorBold, options)
+
+	context_lines := int(options.ContextLines)
+
+	for i := 0; i < len(edits); {
+		if edits[i].Type == EditTypeEqual {
+			i++
+			continue
+		}
+
+		// Found a change. Collect hunk, including all changes separated by no more than two context sizes.
+		hunk_start := max(i-context_lines, 0)
+		hunk_end := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].Type != EditTypeEqual {
+				hunk_end = j + 1
+			} else if j-hunk_end >= 2*context_lines {
+				break
+			}
+		}
+		hunk_end = min(hunk_end+context_lines, len(edits))
+
+		writeHunk(&builder, edits[hunk_start:hunk_end], old_lines, new_lines, options)
+
+		i = hunk_end
+	}
+
+	return builder.String()
+}
+
+func writeHunk(out *strings.Builder, edits []Edit, old_lines []string, new_lines []string, options *UnifiedDiffOptions) {
+
+	old_count := 0
+	new_count := 0
+	for _, edit := range edits {
+		if edit.Type != EditTypeInsert {
+			old_count++
+		}
+		if edit.Type != EditTypeDelete {
+			new_count++
+		}
+	}
+
+	header := fmt.Sprintf(
+		"@@ -%s +%s @@\n",
+		formatHunkRange(edits[0].OldIndex, old_count),
+		formatHunkRange(edits[0].NewIndex, new_count))
+	writeColored(out, header, colorCyan, options)
+
+	for _, edit := range edits {
+		switch edit.Type {
+		case EditTypeEqual:
+			writeDiffLine(out, " ", old_lines[edit.OldIndex], "", options)
+		case EditTypeDelete:
+			writeDiffLine(out, "-", old_lines[edit.OldIndex], colorRed, options)
+		case EditTypeInsert:
+			writeDiffLine(out, "+", new_lines[edit.NewIndex], colorGreen, options)
+		}
+	}
+}
+
+// Range is printed like "start,count", count is omitted if it is 1.
+// Lines are numbered from 1, but for empty ranges line before the range is used.
+func formatHunkRange(start_index int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start_index)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start_index+1)
+	}
+	return fmt.Sprintf("%d,%d", start_index+1, count)
+}
+
+func writeDiffLine(out *strings.Builder, prefix string, line string, color string, options *UnifiedDiffOptions) {
+	if strings.HasSuffix(line, "\n") {
+		writeColored(out, prefix+line, color, options)
+	} else {
+		writeColored(out, prefix+line+"\n", color, options)
+		out.WriteString(noNewlineAtEndOfFile)
+	}
+}
+
+func writeColored(out *strings.Builder, text string, color string, options *UnifiedDiffOptions) {
+	if options.Colored && color != "" {
+		// Put reset sequence before newline.
+		text_without_newline := strings.TrimSuffix(text, "\n")
+		out.WriteString(color)
+		out.WriteString(text_without_newline)
+		out.WriteString(colorReset)
+		out.WriteString(text[len(text_without_newline):])
+	} else {
+		out.WriteString(text)
+	}
+}
+
+// Split text into lines, preserving line endings.
+func SplitLines(text string) []string {
+	result := make([]string, 0)
+	for len(text) > 0 {
+		line_end := strings.IndexByte(text, '\n')
+		if line_end < 0 {
+			result = append(result, text)
+			break
+		}
+		result = append(result, text[:line_end+1])
+		text = text[line_end+1:]
+	}
+
+	return result
+}
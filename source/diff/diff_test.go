@@ -0,0 +1,120 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// Expected results are produced by GNU diff.
+func TestUnified(t *testing.T) {
+	test_cases := []struct {
+		name          string
+		old_text      string
+		new_text      string
+		context_lines uint
+		expected      string
+	}{
+		{
+			name:          "equal",
+			old_text:      "a\nb\n",
+			new_text:      "a\nb\n",
+			context_lines: 3,
+			expected:      "",
+		},
+		{
+			name:          "change in middle",
+			old_text:      "l1\nl2\nl3\nl4\nl5\nl6\nl7\nl8\nl9\nl10\n",
+			new_text:      "l1\nl2\nl3\nl4\nL5\nl6\nl7\nl8\nl9\nl10\n",
+			context_lines: 3,
+			expected:      "--- old.u\n+++ new.u\n@@ -2,7 +2,7 @@\n l2\n l3\n l4\n-l5\n+L5\n l6\n l7\n l8\n",
+		},
+		{
+			name:          "two hunks",
+			old_text:      "l1\nl2\nl3\nl4\nl5\nl6\nl7\nl8\nl9\nl10\nl11\nl12\nl13\nl14\nl15\nl16\nl17\nl18\nl19\nl20\n",
+			new_text:      "l1\nl3\nl4\nl5\nl6\nl7\nl8\nl9\nl10\nl11\nl12\nl13\nl14\nl15\nl16\nl17\nl18\nnew\nl19\nl20\n",
+			context_lines: 3,
+			expected:      "--- old.u\n+++ new.u\n@@ -1,5 +1,4 @@\n l1\n-l2\n l3\n l4\n l5\n@@ -16,5 +15,6 @@\n l16\n l17\n l18\n+new\n l19\n l20\n",
+		},
+		{
+			name:          "merged hunks",
+			old_text:      "l1\nl2\nl3\nl4\nl5\nl6\nl7\nl8\nl9\nl10\n",
+			new_text:      "l1\nl2\nx3\nl4\nl5\nl6\nl7\nx8\nl9\nl10\n",
+			context_lines: 2,
+			expected:      "--- old.u\n+++ new.u\n@@ -1,10 +1,10 @@\n l1\n l2\n-l3\n+x3\n l4\n l5\n l6\n l7\n-l8\n+x8\n l9\n l10\n",
+		},
+		{
+			name:          "zero context",
+			old_text:      "a\nb\nc\n",
+			new_text:      "a\nB\nc\nd\n",
+			context_lines: 0,
+			expected:      "--- old.u\n+++ new.u\n@@ -2 +2 @@\n-b\n+B\n@@ -3,0 +4 @@\n+d\n",
+		},
+		{
+			name:          "no newline at end",
+			old_text:      "a\nb",
+			new_text:      "a\nc",
+			context_lines: 3,
+			expected:      "--- old.u\n+++ new.u\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			name:          "newline added at end",
+			old_text:      "a\nb",
+			new_text:      "a\nb\n",
+			context_lines: 3,
+			expected:      "--- old.u\n+++ new.u\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:          "from empty",
+			old_text:      "",
+			new_text:      "a\nb\n",
+			context_lines: 3,
+			expected:      "--- old.u\n+++ new.u\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:          "to empty",
+			old_text:      "a\nb\n",
+			new_text:      "",
+			context_lines: 3,
+			expected:      "--- old.u\n+++ new.u\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+	}
+
+	for _, test_case := range test_cases {
+		result := Unified("old.u", "new.u", test_case.old_text, test_case.new_text, &UnifiedDiffOptions{ContextLines: test_case.context_lines})
+		if result != test_case.expected {
+			t.Errorf("%s: got\n%s\nexpected\n%s", test_case.name, result, test_case.expected)
+		}
+	}
+}
+
+func TestUnifiedColored(t *testing.T) {
+	result := Unified("old.u", "new.u", "a\nb\n", "a\nc\n", &UnifiedDiffOptions{ContextLines: 3, Colored: true})
+	for _, line := range []string{"-b", "+c", "@@ -1,2 +1,2 @@"} {
+		if !strings.Contains(result, line) {
+			t.Errorf("colored diff %q doesn't contain %q", result, line)
+		}
+	}
+	if !strings.Contains(result, "\x1b[") {
+		t.Errorf("colored diff %q contains no color sequences", result)
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	test_cases := []struct {
+		text  string
+		lines []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a\n"}},
+		{"a\nb", []string{"a\n", "b"}},
+		{"a\r\nb\n\n", []string{"a\r\n", "b\n", "\n"}},
+	}
+
+	for _, test_case := range test_cases {
+		lines := SplitLines(test_case.text)
+		if strings.Join(lines, "|") != strings.Join(test_case.lines, "|") || len(lines) != len(test_case.lines) {
+			t.Errorf("%q: got %q, expected %q", test_case.text, lines, test_case.lines)
+		}
+	}
+}
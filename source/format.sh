@@ -1 +1 @@
-../../go/bin/go fmt
\ No newline at end of file
+../../go/bin/go fmt ./...
@@ -0,0 +1,48 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lextree"
+	"strings"
+)
+
+// Format given program text.
+// Returns error if program can't be lexed or lex tree can't be built.
+func Source(src []byte, opts Options) ([]byte, error) {
+	return SourceLines(src, opts, nil)
+}
+
+// Format only logical lines intersecting given source lines ranges, leaving other text untouched.
+// If ranges list is nil, whole program is formatted.
+func SourceLines(src []byte, opts Options, ranges []LineRange) ([]byte, error) {
+
+	lexems, err := lexer.SplitProgramIntoLexems(string(src))
+	if err != nil {
+		return nil, err
+	}
+
+	normalizeNumbers(lexems, opts.NumberStyle)
+	lexems = joinMacroParameters(lexems)
+
+	lex_tree, err := lextree.BuildLexTree(lexems)
+	if err != nil {
+		return nil, err
+	}
+
+	text_by_lines := SplitLexTreeIntoLines(lex_tree, &opts)
+
+	verbatim_regions := getFormatOffRegions(lexems, string(src))
+	if ranges != nil {
+		verbatim_regions = append(verbatim_regions, getUnselectedLinesRegions(text_by_lines, ranges, string(src))...)
+	}
+
+	text_by_lines = applyVerbatimRegions(text_by_lines, verbatim_regions, string(src))
+	text_formatted := PrintLines(text_by_lines, &opts)
+
+	if strings.HasPrefix(string(src), lexer.ByteOrderMark) && !strings.HasPrefix(text_formatted, lexer.ByteOrderMark) {
+		// Preserve BOM, skipped by the lexer.
+		text_formatted = lexer.ByteOrderMark + text_formatted
+	}
+
+	return []byte(text_formatted), nil
+}
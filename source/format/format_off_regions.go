@@ -0,0 +1,79 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+	"strings"
+)
+
+// Line comments, which disable and enable formatting.
+// Source text between them is copied as is.
+const (
+	FormatOffComment = "format: off"
+	FormatOnComment  = "format: on"
+)
+
+func IsFormatOffComment(lexem *lexer.Lexem) bool {
+	return isSpecialLineComment(lexem, FormatOffComment)
+}
+
+func IsFormatOnComment(lexem *lexer.Lexem) bool {
+	return isSpecialLineComment(lexem, FormatOnComment)
+}
+
+func isSpecialLineComment(lexem *lexer.Lexem, text string) bool {
+	// Ignore extra whitespaces.
+	return lexem.Type == lexer.LexemTypeLineComment && strings.Join(strings.Fields(strings.TrimPrefix(lexem.Text, "//")), " ") == text
+}
+
+// Find regions between "format: off" and "format: on" comments.
+// Marker comments themselves are formatted as usual.
+// If there is some code before "format: on" comment in its line, this comment is included into the region too.
+func getFormatOffRegions(lexems []lexer.Lexem, source string) []verbatimRegion {
+
+	result := make([]verbatimRegion, 0)
+
+	for i := 0; i < len(lexems); i++ {
+		if !IsFormatOffComment(&lexems[i]) {
+			continue
+		}
+
+		off_lexem := &lexems[i]
+		region := verbatimRegion{begin: skipNewline(source, int(off_lexem.Span.End.Offset)), end: len(source)}
+
+		// Search for "format: on" comment.
+		for i++; i < len(lexems); i++ {
+			if IsFormatOnComment(&lexems[i]) {
+				break
+			}
+		}
+
+		if i < len(lexems) {
+			on_lexem := &lexems[i]
+			on_line_start := int(on_lexem.Span.Begin.Offset)
+			for on_line_start > 0 && source[on_line_start-1] != '\n' && source[on_line_start-1] != '\r' {
+				on_line_start--
+			}
+
+			if strings.TrimSpace(source[on_line_start:on_lexem.Span.Begin.Offset]) != "" {
+				region.end = int(on_lexem.Span.End.Offset)
+			} else {
+				region.end = on_line_start
+			}
+		}
+
+		result = append(result, region)
+	}
+
+	return result
+}
+
+// Skip newline sequence at given offset, if it exists.
+func skipNewline(source string, offset int) int {
+	if strings.HasPrefix(source[offset:], "\r\n") {
+		return offset + 2
+	}
+	if offset < len(source) && (source[offset] == '\n' || source[offset] == '\r') {
+		return offset + 1
+	}
+	return offset
+}
@@ -0,0 +1,40 @@
+package format
+
+import (
+	"testing"
+)
+
+func TestByteOrderMarkIsPreserved(t *testing.T) {
+	test_cases := []struct {
+		name     string
+		program  string
+		ranges   []LineRange
+		expected string
+	}{
+		{"whole file", "\uFEFFauto  x=1;", nil, "\uFEFFauto x = 1;\n"},
+		{"no BOM", "auto  x=1;", nil, "auto x = 1;\n"},
+		{"line ranges", "\uFEFFauto  x=1;\nauto  y=2;\n", []LineRange{{First: 2, Last: 2}}, "\uFEFFauto  x=1;\nauto y = 2;\n"},
+		{"format off", "\uFEFF// format: off\nauto  x=1;\n", nil, "\uFEFF// format: off\nauto  x=1;\n"},
+	}
+
+	for _, test_case := range test_cases {
+		result, err := SourceLines([]byte(test_case.program), GetDefaultOptions(), test_case.ranges)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test_case.name, err)
+		} else if string(result) != test_case.expected {
+			t.Errorf("%s: got %q, expected %q", test_case.name, result, test_case.expected)
+		}
+	}
+}
+
+func TestUnicodeIdentifiersFormatting(t *testing.T) {
+	program := "auto  αβγ=变量+Բարեւ*é;"
+	expected := "auto αβγ = 变量 + Բարեւ * é;\n"
+
+	result, err := Source([]byte(program), GetDefaultOptions())
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if string(result) != expected {
+		t.Errorf("got %q, expected %q", result, expected)
+	}
+}
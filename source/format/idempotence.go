@@ -0,0 +1,20 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/diff"
+)
+
+// Format already formatted text once more and compare results.
+// Returns unified diff between first and second formatting passes, or empty string, if formatting is idempotent.
+func VerifyIdempotent(file_name string, formatted []byte, options Options, diff_options *diff.UnifiedDiffOptions) (string, error) {
+	formatted_twice, err := Source(formatted, options)
+	if err != nil {
+		return "", err
+	}
+
+	if string(formatted_twice) == string(formatted) {
+		return "", nil
+	}
+
+	return diff.Unified(file_name+".pass1", file_name+".pass2", string(formatted), string(formatted_twice), diff_options), nil
+}
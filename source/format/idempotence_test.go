@@ -0,0 +1,97 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/diff"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+	"strconv"
+	"testing"
+)
+
+// Format given program, check that lexems are preserved and that formatting of the result changes nothing.
+func checkFormattingIsIdempotent(t testing.TB, src string, options Options) {
+	t.Helper()
+
+	formatted, err := Source([]byte(src), options)
+	if err != nil {
+		t.Fatalf("formatting failed: %s", err)
+	}
+
+	lexems, err := lexer.SplitProgramIntoLexems(src)
+	if err != nil {
+		t.Fatalf("lexing failed: %s", err)
+	}
+	err = VerifyLexemsPreserved(lexems, string(formatted), &options)
+	if err != nil {
+		t.Errorf("lexems are not preserved: %s", err)
+	}
+
+	diff_text, err := VerifyIdempotent("test.u", formatted, options, &diff.UnifiedDiffOptions{ContextLines: 3})
+	if err != nil {
+		t.Fatalf("second formatting pass failed: %s", err)
+	}
+	if diff_text != "" {
+		t.Errorf("second formatting pass changed result:\n%s", diff_text)
+	}
+}
+
+var idempotenceTestPrograms = map[string]string{
+	"functions": `
+namespace NS
+{
+fn Foo( i32 a, i32 b ) : i32 { return a + b; }
+fn Bar(){Foo(1,2);}
+}
+`,
+	"long expressions": `
+fn foo()
+{
+	auto x = some_function_name( first_argument_value, second_argument_value + third_argument_value * fourth(a, b, c), fifth_argument );
+	if( aaaaaaaaaaaaaaa && bbbbbbbbbbbbbbbbbbbbbbbb || cccccccccccccccccccccc && ddddddddddddddddd( eeeeeeeeeeeee, fffffffffff ) ) { return; }
+	var [ i32, 4 ] arr[ 1111111111, 2222222222, 3333333333, 4444444444, 5555555555, 6666666666 ];
+}
+`,
+	"comments": `
+// Line comment.
+struct S
+{
+	i32 x; // Trailing comment.
+	/* Multiline
+	   comment */
+	f32 y;
+
+
+	f64 z;
+}
+`,
+	"literals": `
+auto s = "abc\n"u16 + 'a'c8 + 0xFFu64 + 0b1.01e-3 + 1.5e+3f32;
+`,
+	"macros": `
+?macro <? for2:block ( ?init:expr ; ?cond:expr ) ?body:block ?>
+->
+<? { ?init; while( ?cond ) ?body } auto ??tmp= 0; ?>
+`,
+	"format off": `
+fn foo()
+{
+	// format: off
+	auto   x=1;
+	// format: on
+	auto   y=2;
+}
+`,
+}
+
+func TestFormattingIsIdempotent(t *testing.T) {
+	for name, program := range idempotenceTestPrograms {
+		for style_name, style_options := range StylePresets {
+			for _, max_line_width := range []uint{20, 40, 60, 80, 120} {
+				options := style_options
+				options.MaxLineWidth = max_line_width
+				t.Run(name+"/"+style_name+"/"+strconv.FormatUint(uint64(max_line_width), 10), func(t *testing.T) {
+					checkFormattingIsIdempotent(t, program, options)
+				})
+			}
+		}
+	}
+}
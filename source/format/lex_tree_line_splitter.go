@@ -0,0 +1,207 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lextree"
+	"strings"
+)
+
+// TODO - use better name?
+type LogicalLine = struct {
+	Indentation uint
+	Lexems      []lexer.Lexem
+	Verbatim    string // If not empty, this text is printed as is, instead of lexems.
+}
+
+// Convert lex tree into line by line representation.
+func SplitLexTreeIntoLines(nodes lextree.LexTreeNodeList, options *Options) []LogicalLine {
+
+	result := make([]LogicalLine, 0)
+	addNewLine(&result, 0)
+
+	prev_was_newline := true
+	splitLexTreeIntoLines_r(nodes, 0, options, &result, &prev_was_newline)
+
+	// Remove empty lines at the end.
+	for len(result) > 0 && len(result[len(result)-1].Lexems) == 0 {
+		result = result[:len(result)-1]
+	}
+
+	return result
+}
+
+func splitLexTreeIntoLines_r(
+	nodes lextree.LexTreeNodeList,
+	indentation uint,
+	options *Options,
+	out *[]LogicalLine,
+	prev_was_newline *bool) {
+
+	for i, node := range nodes {
+
+		if isNewlineAfterBraceNeeded(nodes, i) {
+			// At global level add extra empty line.
+			// This ensures that global things like classes or functions are always separated by an empty line.
+			// Inside blocks only source empty lines are preserved.
+			if indentation == 0 || !*prev_was_newline {
+				addNewLine(out, indentation)
+			}
+			*prev_was_newline = true
+		}
+
+		if *prev_was_newline && i > 0 && node.Lexem.NewlinesBefore > 1 {
+			// Preserve empty lines from source, but not at start of the block.
+			// Empty lines at end of the block are not preserved too, since trailing lexem is not checked here.
+			addEmptyLines(out, indentation, min(node.Lexem.NewlinesBefore-1, options.MaxEmptyLines))
+		}
+
+		if node.SubElements == nil {
+
+			appendToLastLine(out, node.Lexem)
+
+			if node.Lexem.Type == lexer.LexemTypeSemicolon {
+
+				// Add newline after ";".
+				// TODO - do this only if it is necessary (allow ";" in single-line "for" operator).
+				addNewLine(out, indentation)
+				*prev_was_newline = true
+
+			} else if node.Lexem.Type == lexer.LexemTypeLineComment {
+
+				// Always add newline after line comment.
+				addNewLine(out, indentation)
+				*prev_was_newline = true
+
+			} else if isMultilineComment(&node.Lexem) {
+
+				// Add newline after multiline comment, which really contains several lines.
+				addNewLine(out, indentation)
+				*prev_was_newline = true
+
+			} else {
+				*prev_was_newline = false
+			}
+
+			if !*prev_was_newline && i > 0 && nodes[i-1].Lexem.Text == "import" {
+				// Add newlines after imports.
+				addNewLine(out, indentation)
+				*prev_was_newline = true
+			}
+
+		} else {
+
+			// TODO - fix  this. Lambdas may contain semicolons, which ruins the whole primary line splitting algorithm.
+			subelements_contain_natural_newlines := hasNaturalNewlines(node.SubElements)
+
+			if subelements_contain_natural_newlines {
+
+				if !*prev_was_newline {
+					addNewLine(out, indentation)
+				}
+
+				appendToLastLine(out, node.Lexem)
+				addNewLine(out, indentation+1)
+				*prev_was_newline = true
+
+			} else {
+				appendToLastLine(out, node.Lexem)
+			}
+
+			// Somewhat hacky namespaces detection.
+			// Assuming "{" follows directly after something like "namespace SomeName".
+			// TODO - skip also comments, newlines, etc. in this check.
+			is_namespace := i >= 2 && nodes[i-1].Lexem.Type == lexer.LexemTypeIdentifier && nodes[i-2].Lexem.Text == "namespace"
+
+			// Hacky template declaration detection.
+			is_template_declaration := node.Lexem.Type == lexer.LexemTypeTemplateBracketLeft && i >= 1 && nodes[i-1].Lexem.Text == "template"
+			_ = is_template_declaration // TODO - use it
+
+			// For namespaces avoid adding extra intendation.
+			// TODO - make this behavior configurabe.
+			sub_elements_indentation := indentation + 1
+			if is_namespace {
+				sub_elements_indentation--
+			}
+
+			splitLexTreeIntoLines_r(
+				node.SubElements,
+				sub_elements_indentation,
+				options,
+				out,
+				prev_was_newline)
+
+			if subelements_contain_natural_newlines {
+
+				if !*prev_was_newline {
+					addNewLine(out, indentation)
+				} else {
+					(*out)[len(*out)-1].Indentation = indentation
+				}
+
+				appendToLastLine(out, node.TrailingLexem)
+				addNewLine(out, indentation)
+				*prev_was_newline = true
+
+			} else {
+
+				appendToLastLine(out, node.TrailingLexem)
+			}
+		}
+	}
+}
+
+func appendToLastLine(lines *[]LogicalLine, lexem lexer.Lexem) {
+	line := &(*lines)[len(*lines)-1]
+	line.Lexems = append(line.Lexems, lexem)
+}
+
+func addNewLine(lines *[]LogicalLine, indentation uint) {
+	*lines = append(*lines, LogicalLine{Indentation: indentation, Lexems: make([]lexer.Lexem, 0)})
+}
+
+// Ensure that last line is preceded by given number of empty lines.
+// Last line itself should be empty.
+func addEmptyLines(lines *[]LogicalLine, indentation uint, count uint) {
+	num_empty_lines := uint(0)
+	for i := len(*lines) - 2; i >= 0 && len((*lines)[i].Lexems) == 0; i-- {
+		num_empty_lines++
+	}
+
+	for ; num_empty_lines < count; num_empty_lines++ {
+		addNewLine(lines, indentation)
+	}
+}
+
+// Newline is added after "}", except it is followed by ";", "else", ".", ",".
+func isNewlineAfterBraceNeeded(nodes lextree.LexTreeNodeList, i int) bool {
+	if i == 0 || nodes[i-1].TrailingLexem.Type != lexer.LexemTypeBraceRight {
+		return false
+	}
+
+	lexem := &nodes[i].Lexem
+	return !(lexem.Type == lexer.LexemTypeSemicolon || lexem.Type == lexer.LexemTypeDot || lexem.Type == lexer.LexemTypeComma || lexem.Text == "else")
+}
+
+func hasNaturalNewlines(nodes lextree.LexTreeNodeList) bool {
+
+	for i, node := range nodes {
+		if node.Lexem.Type == lexer.LexemTypeLineComment || node.Lexem.Type == lexer.LexemTypeSemicolon || isMultilineComment(&node.Lexem) {
+			return true
+		}
+
+		if isNewlineAfterBraceNeeded(nodes, i) {
+			return true
+		}
+
+		if hasNaturalNewlines(node.SubElements) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns true for /* */ comments with newlines inside.
+func isMultilineComment(lexem *lexer.Lexem) bool {
+	return lexem.Type == lexer.LexemTypeMultilineComment && strings.Contains(lexem.Text, "\n")
+}
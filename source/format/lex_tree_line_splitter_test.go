@@ -0,0 +1,82 @@
+package format
+
+import (
+	"testing"
+)
+
+func TestEmptyLinesPreservation(t *testing.T) {
+	test_cases := []struct {
+		program         string
+		max_empty_lines uint
+		expected        string
+	}{
+		{
+			// Single empty line is preserved.
+			"fn f()\n{\n\ta;\n\n\tb;\n}\n",
+			1,
+			"fn f()\n{\n\ta;\n\n\tb;\n}\n",
+		},
+		{
+			// Long sequences of empty lines are collapsed.
+			"fn f()\n{\n\ta;\n\n\n\n\tb;\n}\n",
+			1,
+			"fn f()\n{\n\ta;\n\n\tb;\n}\n",
+		},
+		{
+			"fn f()\n{\n\ta;\n\n\n\n\tb;\n}\n",
+			2,
+			"fn f()\n{\n\ta;\n\n\n\tb;\n}\n",
+		},
+		{
+			"fn f()\n{\n\ta;\n\n\tb;\n}\n",
+			0,
+			"fn f()\n{\n\ta;\n\tb;\n}\n",
+		},
+		{
+			// Empty lines at start and end of the block are removed.
+			"fn f()\n{\n\n\ta;\n\n}\n",
+			1,
+			"fn f()\n{\n\ta;\n}\n",
+		},
+		{
+			// Global things are always separated by an empty line.
+			"fn f(){ a; }\nfn g(){ b; }\n",
+			0,
+			"fn f()\n{\n\ta;\n}\n\nfn g()\n{\n\tb;\n}\n",
+		},
+		{
+			// No empty line is added after "}" inside blocks, but newline is still added.
+			"struct S\n{\n\tfn f(){}\n\tfn g(){}\n}\n",
+			1,
+			"struct S\n{\n\tfn f(){}\n\tfn g(){}\n}\n",
+		},
+		{
+			"struct S\n{\n\tfn f(){}\n\n\tfn g(){}\n}\n",
+			1,
+			"struct S\n{\n\tfn f(){}\n\n\tfn g(){}\n}\n",
+		},
+		{
+			"fn f()\n{\n\tif(a){ b; }\n\n\n\tc;\n}\n",
+			1,
+			"fn f()\n{\n\tif( a )\n\t{\n\t\tb;\n\t}\n\n\tc;\n}\n",
+		},
+		{
+			// No newline before "else".
+			"fn f()\n{\n\tif(a){ b; } else { c; }\n}\n",
+			1,
+			"fn f()\n{\n\tif( a )\n\t{\n\t\tb;\n\t}\n\telse\n\t{\n\t\tc;\n\t}\n}\n",
+		},
+	}
+
+	for _, test_case := range test_cases {
+		options := GetDefaultOptions()
+		options.MaxEmptyLines = test_case.max_empty_lines
+
+		result, err := Source([]byte(test_case.program), options)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test_case.program, err)
+		} else if string(result) != test_case.expected {
+			t.Errorf("%q: got %q, expected %q", test_case.program, result, test_case.expected)
+		}
+	}
+}
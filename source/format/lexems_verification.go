@@ -0,0 +1,86 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+)
+
+// Check that formatted text contains exactly the same lexems as the source.
+// Numbers may differ only if number normalization is enabled in given options.
+// Not normalized numbers are allowed too, since they are kept in verbatim regions.
+// Returns error describing the first divergence, if lexems differ.
+func VerifyLexemsPreserved(source_lexems []lexer.Lexem, formatted string, options *Options) error {
+	formatted_lexems, err := lexFormattedText(formatted)
+	if err != nil {
+		return err
+	}
+
+	return compareLexems(source_lexems, formatted_lexems, options)
+}
+
+func lexFormattedText(formatted string) ([]lexer.Lexem, error) {
+	formatted_lexems, err := lexer.SplitProgramIntoLexems(formatted)
+	if err != nil {
+		var src_error *lexer.SrcError
+		if errors.As(err, &src_error) {
+			return nil, lexer.NewSrcError(src_error.Pos, "formatted text can not be lexed: %s", src_error.Text)
+		}
+		return nil, err
+	}
+	return formatted_lexems, nil
+}
+
+func compareLexems(source_lexems []lexer.Lexem, formatted_lexems []lexer.Lexem, options *Options) error {
+
+	for i := range source_lexems {
+		source_lexem := &source_lexems[i]
+		if i >= len(formatted_lexems) || (formatted_lexems[i].Type == lexer.LexemTypeEndOfFile && source_lexem.Type != lexer.LexemTypeEndOfFile) {
+			return lexer.NewSrcError(source_lexem.Span.Begin, "formatting dropped lexem \"%s\"", source_lexem.Text)
+		}
+
+		formatted_lexem := &formatted_lexems[i]
+		if source_lexem.Type == lexer.LexemTypeEndOfFile && formatted_lexem.Type != lexer.LexemTypeEndOfFile {
+			return newLexemAddedError(formatted_lexem)
+		}
+
+		expected_text := getExpectedLexemText(source_lexem, options)
+		if source_lexem.Type != formatted_lexem.Type || (formatted_lexem.Text != expected_text && formatted_lexem.Text != source_lexem.Text) {
+			normalization := ""
+			if expected_text != source_lexem.Text {
+				normalization = fmt.Sprintf(" (normalized from \"%s\")", source_lexem.Text)
+			}
+			return lexer.NewSrcError(
+				source_lexem.Span.Begin,
+				"formatting changed lexem \"%s\"%s into \"%s\" at %d:%d of formatted text",
+				expected_text,
+				normalization,
+				formatted_lexem.Text,
+				formatted_lexem.Span.Begin.Line,
+				formatted_lexem.Span.Begin.Column)
+		}
+	}
+
+	if len(formatted_lexems) > len(source_lexems) {
+		return newLexemAddedError(&formatted_lexems[len(source_lexems)])
+	}
+
+	return nil
+}
+
+func newLexemAddedError(formatted_lexem *lexer.Lexem) error {
+	return lexer.NewSrcError(
+		formatted_lexem.Span.Begin,
+		"formatting added lexem \"%s\" at %d:%d of formatted text",
+		formatted_lexem.Text,
+		formatted_lexem.Span.Begin.Line,
+		formatted_lexem.Span.Begin.Column)
+}
+
+// Formatted lexem should be the same as the source one or its normalized version.
+func getExpectedLexemText(source_lexem *lexer.Lexem, options *Options) string {
+	if source_lexem.Type == lexer.LexemTypeNumber && options.NumberStyle != NumberStylePreserve {
+		return normalizeNumber(source_lexem.Text, options.NumberStyle)
+	}
+	return source_lexem.Text
+}
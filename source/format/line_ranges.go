@@ -0,0 +1,105 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+)
+
+// Range of source lines, numbered from 1, inclusive.
+type LineRange struct {
+	First uint
+	Last  uint
+}
+
+// Find regions of source text, corresponding to logical lines not intersecting given source lines ranges.
+// Logical lines, which share source lines with selected logical lines, are selected too,
+// so that region boundaries are always at starts of source lines.
+func getUnselectedLinesRegions(lines []LogicalLine, ranges []LineRange, source string) []verbatimRegion {
+
+	line_starts := lexer.GetLineStartOffsets(source)
+	num_source_lines := uint(len(line_starts))
+
+	// Index is source line number.
+	selected := make([]bool, num_source_lines+2)
+	for _, r := range ranges {
+		for l := max(r.First, 1); l <= min(r.Last, num_source_lines); l++ {
+			selected[l] = true
+		}
+	}
+
+	type LineSpan struct {
+		first uint
+		last  uint
+	}
+
+	spans := make([]LineSpan, len(lines))
+	for i, line := range lines {
+		if len(line.Lexems) > 0 {
+			spans[i] = LineSpan{first: line.Lexems[0].Span.Begin.Line, last: line.Lexems[len(line.Lexems)-1].Span.End.Line}
+		}
+	}
+
+	is_line_selected := func(span LineSpan) bool {
+		for l := span.first; l <= span.last; l++ {
+			if selected[l] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Extend selection until all source lines of selected logical lines are selected.
+	for changed := true; changed; {
+		changed = false
+		for i := range lines {
+			if len(lines[i].Lexems) == 0 || !is_line_selected(spans[i]) {
+				continue
+			}
+			for l := spans[i].first; l <= spans[i].last; l++ {
+				if !selected[l] {
+					selected[l] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	line_start_offset := func(line uint) int {
+		if line > num_source_lines {
+			return len(source)
+		}
+		return int(line_starts[line-1])
+	}
+
+	result := make([]verbatimRegion, 0)
+
+	region_begin := 0
+	in_region := false
+	has_selected_lines := false
+	for i := range lines {
+		if len(lines[i].Lexems) == 0 {
+			continue
+		}
+
+		if is_line_selected(spans[i]) {
+			has_selected_lines = true
+			if in_region {
+				result = append(result, verbatimRegion{begin: region_begin, end: line_start_offset(spans[i].first)})
+				in_region = false
+			}
+			region_begin = line_start_offset(spans[i].last + 1)
+		} else {
+			in_region = true
+		}
+	}
+
+	if !has_selected_lines {
+		// Nothing to format (possibly source without lexems) - keep whole source untouched.
+		return []verbatimRegion{{begin: 0, end: len(source)}}
+	}
+
+	if in_region {
+		result = append(result, verbatimRegion{begin: region_begin, end: len(source)})
+	}
+
+	return result
+}
@@ -0,0 +1,930 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lextree"
+	"strings"
+)
+
+// Convert line-by-line representation into text representation, split too ling lines if necessary.
+func PrintLines(lines []LogicalLine, options *Options) string {
+
+	text_builder := strings.Builder{}
+
+	for line_index, line := range lines {
+
+		if line.Verbatim != "" {
+			// Add newline after verbatim text, if it is necessary, except verbatim text at the end of file.
+			text_builder.WriteString(line.Verbatim)
+			is_last_line := line_index+1 == len(lines)
+			if !is_last_line && !strings.HasSuffix(line.Verbatim, "\n") && !strings.HasSuffix(line.Verbatim, "\r") {
+				text_builder.WriteString(options.LineEndSequence)
+			}
+			continue
+		}
+
+		if len(line.Lexems) == 0 {
+			// Do not add indentation for empty lines.
+			text_builder.WriteString(options.LineEndSequence)
+			continue
+		}
+
+		line_builder := strings.Builder{}
+
+		for i := uint(0); i < line.Indentation; i++ {
+			line_builder.WriteString(options.IndentationSequence)
+		}
+
+		line_width := countIndentationsSize(line.Indentation, options)
+		has_multiline_comments := false
+
+		for i, lexem := range line.Lexems {
+			if i > 0 && whitespaceIsNeeded(&line.Lexems[i-1], &lexem) {
+				line_builder.WriteString(" ")
+				line_width++
+			}
+
+			if isMultilineComment(&lexem) {
+				writeMultilineComment(&line_builder, &lexem, line.Indentation, options)
+				has_multiline_comments = true
+			} else {
+				line_builder.WriteString(lexem.Text)
+				line_width += uint(len(lexem.Text))
+			}
+		}
+
+		line_builder.WriteString(options.LineEndSequence)
+
+		if line_width <= options.MaxLineWidth || has_multiline_comments {
+			// Fine - line width does not exeed the limit.
+			// Lines with multiline comments are never splitted.
+			text_builder.WriteString(line_builder.String())
+		} else {
+			// Try to split this line.
+			// Build lex_tree again, but only for this line.
+			lex_tree, err := lextree.BuildLexTree(line.Lexems)
+			if err != nil {
+				// Fallback for unlikely cases.
+				text_builder.WriteString(line_builder.String())
+			} else {
+				line_splitted := printAndSplitLexTree(lex_tree, line.Indentation, options)
+				text_builder.WriteString(line_splitted)
+			}
+		}
+	}
+
+	return text_builder.String()
+}
+
+// Write multiline comment text, adding given indentation for each comment line except first one.
+// Empty lines are not indented.
+func writeMultilineComment(out *strings.Builder, lexem *lexer.Lexem, indentation uint, options *Options) {
+	for i, line := range strings.Split(lexem.Text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if i > 0 {
+			out.WriteString(options.LineEndSequence)
+			if len(line) > 0 {
+				for i := uint(0); i < indentation; i++ {
+					out.WriteString(options.IndentationSequence)
+				}
+			}
+		}
+		out.WriteString(line)
+	}
+}
+
+func whitespaceIsNeeded(l *lexer.Lexem, r *lexer.Lexem) bool {
+	switch r.Type {
+	case lexer.LexemTypeNone:
+
+	case lexer.LexemTypeLineComment:
+		return true
+
+	case lexer.LexemTypeMultilineComment:
+		return true
+
+	case lexer.LexemTypeIdentifier:
+		if l.Type == lexer.LexemTypeDot || l.Type == lexer.LexemTypeScope || l.Type == lexer.LexemTypeTemplateBracketRight {
+			return false
+		}
+		if l.Type == lexer.LexemTypeAnd {
+			if r.Text == "mut" || r.Text == "imut" || r.Text == "constexpr" {
+				// Allow "&mut", "&imut", "&constexpr".
+				return false
+			}
+		}
+		return true
+
+	case lexer.LexemTypeMacroIdentifier:
+		return true
+
+	case lexer.LexemTypeMacroUniqueIdentifier:
+		return true
+
+	case lexer.LexemTypeString:
+		return true
+
+	case lexer.LexemTypeNumber:
+		return true
+
+	case lexer.LexemTypeLiteralSuffix:
+
+	case lexer.LexemTypeBracketLeft:
+		if l.Type == lexer.LexemTypeBracketLeft {
+			return true
+		}
+		if isJoinedMacroMatcherElement(l) {
+			// Elements of macro matchers are not callable.
+			return true
+		}
+		return false
+
+	case lexer.LexemTypeBracketRight:
+		if l.Type == lexer.LexemTypeBracketLeft {
+			return false
+		}
+		return true
+
+	case lexer.LexemTypeSquareBracketLeft:
+		return false
+
+	case lexer.LexemTypeSquareBracketRight:
+		return false
+
+	case lexer.LexemTypeBraceLeft:
+		return false
+
+	case lexer.LexemTypeBraceRight:
+		return false
+
+	case lexer.LexemTypeTemplateBracketLeft:
+		return false
+
+	case lexer.LexemTypeTemplateBracketRight:
+		return true
+
+	case lexer.LexemTypeMacroBracketLeft:
+		return true
+
+	case lexer.LexemTypeMacroBracketRight:
+		return true
+
+	case lexer.LexemTypeScope:
+		return false
+
+	case lexer.LexemTypeComma:
+		return false
+
+	case lexer.LexemTypeDot:
+		if l.Type == lexer.LexemTypeComma {
+			// Add spaces in struct named initializer before ".".
+			// But in member access use no space before ".".
+			return true
+		}
+		return false
+
+	case lexer.LexemTypeColon:
+		return true
+
+	case lexer.LexemTypeSemicolon:
+		return false
+
+	case lexer.LexemTypeQuestion:
+		return true
+
+	case lexer.LexemTypeAssignment:
+		return true
+
+	case lexer.LexemTypePlus:
+		return true
+
+	case lexer.LexemTypeMinus:
+		return true // TODO - detect unary minus
+
+	case lexer.LexemTypeStar:
+		return true
+
+	case lexer.LexemTypeSlash:
+		return true
+
+	case lexer.LexemTypePercent:
+		return true
+
+	case lexer.LexemTypeAnd:
+		// TODO - check cases with &mut
+		// TODO - check case wih auto&
+		// TODO - check case like var i32& x
+		return true
+
+	case lexer.LexemTypeOr:
+		return true
+
+	case lexer.LexemTypeXor:
+		return true
+
+	case lexer.LexemTypeTilda:
+		return false
+
+	case lexer.LexemTypeNot:
+		return false
+
+	case lexer.LexemTypeApostrophe:
+		return true
+
+	case lexer.LexemTypeAt:
+		return true
+
+	case lexer.LexemTypeIncrement:
+		return false
+
+	case lexer.LexemTypeDecrement:
+		return false
+
+	case lexer.LexemTypeCompareLess:
+		return true
+
+	case lexer.LexemTypeCompareGreater:
+		return true
+
+	case lexer.LexemTypeCompareEqual:
+		return true
+
+	case lexer.LexemTypeCompareNotEqual:
+		return true
+
+	case lexer.LexemTypeCompareLessOrEqual:
+		return true
+
+	case lexer.LexemTypeCompareGreaterOrEqual:
+		return true
+
+	case lexer.LexemTypeCompareOrder:
+		return true
+
+	case lexer.LexemTypeConjunction:
+		return true
+
+	case lexer.LexemTypeDisjunction:
+		return true
+
+	case lexer.LexemTypeAssignAdd:
+		return true
+
+	case lexer.LexemTypeAssignSub:
+		return true
+
+	case lexer.LexemTypeAssignMul:
+		return true
+
+	case lexer.LexemTypeAssignDiv:
+		return true
+
+	case lexer.LexemTypeAssignRem:
+		return true
+
+	case lexer.LexemTypeAssignAnd:
+		return true
+
+	case lexer.LexemTypeAssignOr:
+		return true
+
+	case lexer.LexemTypeAssignXor:
+		return true
+
+	case lexer.LexemTypeShiftLeft:
+		return true
+
+	case lexer.LexemTypeShiftRight:
+		return true
+
+	case lexer.LexemTypeAssignShiftLeft:
+		return true
+
+	case lexer.LexemTypeAssignShiftRight:
+		return true
+
+	case lexer.LexemTypeRightArrow:
+		return true
+
+	case lexer.LexemTypePointerTypeMark:
+		return true
+
+	case lexer.LexemTypeReferenceToPointer:
+		return true
+
+	case lexer.LexemTypePointerToReference:
+		return true
+
+	case lexer.LexemTypeEllipsis:
+		return true
+
+	case lexer.LexemTypeEndOfFile:
+		return false
+	}
+
+	return true
+}
+
+func printAndSplitLexTree(nodes lextree.LexTreeNodeList, indentation uint, options *Options) string {
+	builder := strings.Builder{}
+
+	for i := uint(0); i < indentation; i++ {
+		builder.WriteString(options.IndentationSequence)
+	}
+
+	current_line_width := countIndentationsSize(indentation, options)
+
+	printAndSplitLexTree_r(nodes, options, &builder, indentation, &current_line_width)
+
+	builder.WriteString(options.LineEndSequence)
+
+	return builder.String()
+}
+
+// Main recursive routine for splitting of lex_tree into multiple lines.
+// Has exponentioal complexity, but it should not be a big problem, since single-line lexical trees are pretty small.
+func printAndSplitLexTree_r(
+	nodes lextree.LexTreeNodeList,
+	options *Options,
+	out *strings.Builder,
+	indentation uint,
+	current_line_width *uint) {
+
+	split_results := make([]splittingResult, 0)
+
+	// Perform split at current level to give it more priority.
+	current_level_split_result := splitNodeListAtCurrentLevel(nodes, options, indentation, *current_line_width)
+	if current_level_split_result != nil {
+		split_results = append(split_results, *current_level_split_result)
+	}
+
+	further_level_split_result := printAndSplitNodeListAtFurtherLevels(nodes, options, indentation, *current_line_width)
+	split_results = append(split_results, further_level_split_result)
+
+	best_result := chooseBestSplitResult(split_results, *current_line_width, options)
+	out.WriteString(best_result.text)
+	*current_line_width = best_result.current_line_width
+}
+
+func splitNodeListAtCurrentLevel(
+	nodes lextree.LexTreeNodeList,
+	options *Options,
+	indentation uint,
+	current_line_width uint) *splittingResult {
+
+	if len(nodes) <= 1 {
+		return nil // Can-t split single node.
+	}
+
+	// Recursively split and print this list, adding newlines in split points.
+	builder := strings.Builder{}
+
+	// Search for the most important lexem type to use it as splitter.
+	// Ignore last node, because splitting at last node has no sense.
+	max_priority := 0
+	for _, node := range nodes[:len(nodes)-1] {
+		priority := getLineSplitLexemPriority(&node.Lexem)
+		if priority > max_priority {
+			max_priority = priority
+		}
+	}
+
+	// Split this lexems list into parts, using maximum priority lexem type.
+	// Add newline after each part.
+	last_i := 0
+	next_indentation := indentation
+	for i := 0; i < len(nodes)-1; i++ {
+
+		if getLineSplitLexemPriority(&nodes[i].Lexem) == max_priority {
+
+			printAndSplitLexTree_r(nodes[last_i:i+1], options, &builder, next_indentation, &current_line_width)
+			last_i = i + 1
+
+			next_indentation = indentation + 1
+
+			builder.WriteString(options.LineEndSequence)
+			for i := uint(0); i < next_indentation; i++ {
+				builder.WriteString(options.IndentationSequence)
+			}
+			current_line_width = countIndentationsSize(next_indentation, options)
+		}
+	}
+
+	// Process last segment specially.
+	printAndSplitLexTree_r(nodes[last_i:], options, &builder, next_indentation, &current_line_width)
+
+	return &splittingResult{current_line_width: current_line_width, text: builder.String()}
+}
+
+func printAndSplitNodeListAtFurtherLevels(
+	nodes lextree.LexTreeNodeList,
+	options *Options,
+	indentation uint,
+	current_line_width uint) splittingResult {
+
+	builder := strings.Builder{}
+	printAndSplitNodeListAtFurtherLevelsImpl(nodes, options, &builder, indentation, &current_line_width)
+	return splittingResult{current_line_width: current_line_width, text: builder.String()}
+}
+
+func printAndSplitNodeListAtFurtherLevelsImpl(
+	nodes lextree.LexTreeNodeList,
+	options *Options,
+	out *strings.Builder,
+	indentation uint,
+	current_line_width *uint) {
+
+	for i, node := range nodes {
+
+		if node.SubElements == nil {
+
+			if i > 0 && whitespaceIsNeeded(&nodes[i-1].Lexem, &node.Lexem) {
+				out.WriteString(" ")
+				*current_line_width++
+			}
+
+			out.WriteString(node.Lexem.Text)
+			*current_line_width += uint(len(node.Lexem.Text))
+
+		} else {
+
+			if node.Lexem.Type == lexer.LexemTypeBracketLeft {
+				printAndSplitBracketsNode(&node, options, out, indentation, current_line_width)
+			} else if node.Lexem.Type == lexer.LexemTypeBraceLeft {
+				printAndSplitBracesNode(&node, options, out, indentation, current_line_width)
+			} else {
+
+				out.WriteString(node.Lexem.Text)
+				*current_line_width += uint(len(node.Lexem.Text))
+
+				if len(node.SubElements) > 0 {
+					out.WriteString(" ")
+					*current_line_width++
+				}
+
+				printAndSplitLexTree_r(node.SubElements, options, out, indentation, current_line_width)
+
+				if len(node.SubElements) > 0 {
+					out.WriteString(" ")
+					*current_line_width++
+				}
+
+				out.WriteString(node.TrailingLexem.Text)
+				*current_line_width += uint(len(node.TrailingLexem.Text))
+			}
+		}
+	}
+}
+
+func printAndSplitBracketsNode(
+	node *lextree.LexTreeNode,
+	options *Options,
+	out *strings.Builder,
+	indentation uint,
+	current_line_width *uint) {
+
+	if len(node.SubElements) == 0 {
+
+		out.WriteString(node.Lexem.Text)
+		*current_line_width += uint(len(node.Lexem.Text))
+
+		out.WriteString(node.TrailingLexem.Text)
+		*current_line_width += uint(len(node.TrailingLexem.Text))
+
+		return
+	}
+
+	current_split_result := printAndSplitBracketsNodeAtCurrentLevel(node, options, indentation, *current_line_width)
+	further_split_result := printAndSplitBracketsNodeAtFurtherLevels(node, options, indentation, *current_line_width)
+
+	if len(current_split_result.text) == 0 {
+		out.WriteString(further_split_result.text)
+		*current_line_width = further_split_result.current_line_width
+		return
+	}
+
+	arr := [...]splittingResult{current_split_result, further_split_result}
+	best_result := chooseBestSplitResult(arr[:], *current_line_width, options)
+
+	out.WriteString(best_result.text)
+	*current_line_width = best_result.current_line_width
+}
+
+// Returns empty result in case of fail.
+func printAndSplitBracketsNodeAtCurrentLevel(
+	node *lextree.LexTreeNode,
+	options *Options,
+	indentation uint,
+	current_line_width uint) splittingResult {
+
+	if len(node.SubElements) <= 1 {
+		return splittingResult{}
+	}
+
+	/* // For () try to create newlines in style like this:
+	Foo(
+		a,
+		b,
+		c );
+	// Or something like this:
+	Bar(
+		"a" +
+		"b" +
+		"c" );
+	*/
+
+	// Recursively split and print this list, adding newlines before split points.
+	builder := strings.Builder{}
+
+	builder.WriteString(node.Lexem.Text)
+	current_line_width += uint(len(node.Lexem.Text))
+
+	// Search for the most important lexem type to use it as splitter.
+	// Ignore last node, because splitting at last node has no sense.
+	max_priority := 0
+	for _, node := range node.SubElements[:len(node.SubElements)-1] {
+		priority := getLineSplitLexemPriority(&node.Lexem)
+		if priority > max_priority {
+			max_priority = priority
+		}
+	}
+
+	// Split this lexems list into parts, using maximum priority lexem type.
+	// Add newline befpre each part.
+	last_i := 0
+	for i := 0; i < len(node.SubElements); i++ {
+
+		if getLineSplitLexemPriority(&node.SubElements[i].Lexem) == max_priority ||
+			i+1 == len(node.SubElements) {
+
+			builder.WriteString(options.LineEndSequence)
+			for i := uint(0); i < indentation+1; i++ {
+				builder.WriteString(options.IndentationSequence)
+			}
+			current_line_width = countIndentationsSize(indentation+1, options)
+
+			printAndSplitLexTree_r(node.SubElements[last_i:i+1], options, &builder, indentation+1, &current_line_width)
+			last_i = i + 1
+		}
+	}
+
+	builder.WriteString(" ")
+	current_line_width++
+	builder.WriteString(node.TrailingLexem.Text)
+	current_line_width += uint(len(node.TrailingLexem.Text))
+
+	return splittingResult{current_line_width: current_line_width, text: builder.String()}
+}
+
+func printAndSplitBracketsNodeAtFurtherLevels(
+	node *lextree.LexTreeNode,
+	options *Options,
+	indentation uint,
+	current_line_width uint) splittingResult {
+
+	builder := strings.Builder{}
+
+	builder.WriteString(node.Lexem.Text)
+	current_line_width += uint(len(node.Lexem.Text))
+	builder.WriteString(" ")
+	current_line_width++
+
+	printAndSplitNodeListAtFurtherLevelsImpl(node.SubElements, options, &builder, indentation, &current_line_width)
+
+	builder.WriteString(" ")
+	current_line_width++
+	builder.WriteString(node.TrailingLexem.Text)
+	current_line_width += uint(len(node.TrailingLexem.Text))
+
+	return splittingResult{current_line_width: current_line_width, text: builder.String()}
+}
+
+func printAndSplitBracesNode(
+	node *lextree.LexTreeNode,
+	options *Options,
+	out *strings.Builder,
+	indentation uint,
+	current_line_width *uint) {
+
+	if len(node.SubElements) == 0 {
+
+		out.WriteString(node.Lexem.Text)
+		*current_line_width += uint(len(node.Lexem.Text))
+
+		out.WriteString(node.TrailingLexem.Text)
+		*current_line_width += uint(len(node.TrailingLexem.Text))
+
+		return
+	}
+
+	current_split_result := printAndSplitBracesNodeAtCurrentLevel(node, options, indentation, *current_line_width)
+	further_split_result := printAndSplitBracesNodeAtFurtherLevels(node, options, indentation, *current_line_width)
+
+	if len(current_split_result.text) == 0 {
+		out.WriteString(further_split_result.text)
+		*current_line_width = further_split_result.current_line_width
+		return
+	}
+
+	arr := [...]splittingResult{current_split_result, further_split_result}
+	best_result := chooseBestSplitResult(arr[:], *current_line_width, options)
+
+	out.WriteString(best_result.text)
+	*current_line_width = best_result.current_line_width
+}
+
+// Returns empty result in case of fail.
+func printAndSplitBracesNodeAtCurrentLevel(
+	node *lextree.LexTreeNode,
+	options *Options,
+	indentation uint,
+	current_line_width uint) splittingResult {
+
+	if len(node.SubElements) <= 1 {
+		return splittingResult{}
+	}
+
+	/* // For {} try to create newlines in style like this:
+		Foo
+		{
+			a,
+			b,
+			c
+		}.Some();
+	// Or something like this:
+	Foo
+		{
+			"a" +
+			"b" +
+			"c"
+		}.Some();
+	*/
+
+	// Recursively split and print this list, adding newlines before split points.
+	builder := strings.Builder{}
+
+	builder.WriteString(options.LineEndSequence)
+	for i := uint(0); i < indentation+1; i++ {
+		builder.WriteString(options.IndentationSequence)
+	}
+	current_line_width = countIndentationsSize(indentation, options)
+
+	builder.WriteString(node.Lexem.Text)
+	current_line_width += uint(len(node.Lexem.Text))
+
+	// Search for the most important lexem type to use it as splitter.
+	// Ignore last node, because splitting at last node has no sense.
+	max_priority := 0
+	for _, node := range node.SubElements[:len(node.SubElements)-1] {
+		priority := getLineSplitLexemPriority(&node.Lexem)
+		if priority > max_priority {
+			max_priority = priority
+		}
+	}
+
+	// Split this lexems list into parts, using maximum priority lexem type.
+	// Add newline befpre each part.
+	last_i := 0
+	for i := 0; i < len(node.SubElements); i++ {
+
+		if getLineSplitLexemPriority(&node.SubElements[i].Lexem) == max_priority ||
+			i+1 == len(node.SubElements) {
+
+			builder.WriteString(options.LineEndSequence)
+			for i := uint(0); i < indentation+2; i++ {
+				builder.WriteString(options.IndentationSequence)
+			}
+			current_line_width = countIndentationsSize(indentation+1, options)
+
+			printAndSplitLexTree_r(node.SubElements[last_i:i+1], options, &builder, indentation+2, &current_line_width)
+			last_i = i + 1
+		}
+	}
+
+	builder.WriteString(options.LineEndSequence)
+	for i := uint(0); i < indentation+1; i++ {
+		builder.WriteString(options.IndentationSequence)
+	}
+	current_line_width = countIndentationsSize(indentation, options)
+
+	builder.WriteString(node.TrailingLexem.Text)
+	current_line_width += uint(len(node.TrailingLexem.Text))
+
+	return splittingResult{current_line_width: current_line_width, text: builder.String()}
+}
+
+func printAndSplitBracesNodeAtFurtherLevels(
+	node *lextree.LexTreeNode,
+	options *Options,
+	indentation uint,
+	current_line_width uint) splittingResult {
+
+	builder := strings.Builder{}
+
+	builder.WriteString(node.Lexem.Text)
+	current_line_width += uint(len(node.Lexem.Text))
+	builder.WriteString(" ")
+	current_line_width++
+
+	printAndSplitNodeListAtFurtherLevelsImpl(node.SubElements, options, &builder, indentation, &current_line_width)
+
+	builder.WriteString(" ")
+	current_line_width++
+	builder.WriteString(node.TrailingLexem.Text)
+	current_line_width += uint(len(node.TrailingLexem.Text))
+
+	return splittingResult{current_line_width: current_line_width, text: builder.String()}
+}
+
+type splittingResult struct {
+	current_line_width uint
+	text               string
+}
+
+// Choose best result based on number of lines.
+// If number of lines is equal, choose firs result with such number.
+func chooseBestSplitResult(
+	results []splittingResult, current_line_width uint, options *Options) *splittingResult {
+
+	if len(results) == 0 {
+		panic("No splitting results!")
+	}
+	if len(results) == 1 {
+		return &results[0]
+	}
+
+	type ResultStats struct {
+		num_lines                uint
+		exceeds_line_width_limit bool
+	}
+
+	stats := make([]ResultStats, len(results))
+
+	for i, r := range results {
+
+		num_newlines := uint(0)
+		max_line_width := uint(0)
+		w := current_line_width
+
+		for _, c := range r.text {
+			if c == '\n' { // TODO - use newline sequence from options.
+				max_line_width = max(max_line_width, w)
+				w = 0
+				num_newlines++
+			} else if c == '\t' {
+				w += options.TabSize
+			} else {
+				w++
+			}
+		}
+
+		max_line_width = max(max_line_width, w)
+
+		stats[i].num_lines = num_newlines
+		stats[i].exceeds_line_width_limit = max_line_width > options.MaxLineWidth
+	}
+
+	num_results_over_line_limit := uint(0)
+	for _, s := range stats {
+		if s.exceeds_line_width_limit {
+			num_results_over_line_limit++
+		}
+	}
+
+	var res *splittingResult = nil
+	min_num_lines := uint(1024 * 1024)
+
+	if num_results_over_line_limit < uint(len(stats)) {
+		for i, r := range results {
+			if !stats[i].exceeds_line_width_limit && stats[i].num_lines < min_num_lines {
+				min_num_lines = stats[i].num_lines
+				res = &r
+			}
+		}
+	} else {
+		// Fallback in cases where it isn't possible to split below the limit.
+		for i, r := range results {
+			if stats[i].num_lines < min_num_lines {
+				min_num_lines = stats[i].num_lines
+				res = &r
+			}
+		}
+	}
+
+	if res == nil {
+		panic("Unexpected missing result!")
+	}
+	return res
+}
+
+func countNewlines(s string) uint {
+	// TODO - use newline sequence from options.
+	count := uint(0)
+	for _, c := range s {
+		if c == '\n' {
+			count++
+		}
+	}
+
+	return count
+}
+
+func countIndentationsSize(indentation uint, options *Options) uint {
+	count := uint(0)
+	for _, c := range options.IndentationSequence {
+		if c == '\t' {
+			count += options.TabSize
+		} else {
+			count++
+		}
+	}
+
+	return count * indentation
+}
+
+// More priority - more likely to split.
+func getLineSplitLexemPriority(l *lexer.Lexem) int {
+	switch l.Type {
+
+	case lexer.LexemTypeLineComment:
+		return 200
+
+	case lexer.LexemTypeSemicolon:
+		return 100
+
+	case lexer.LexemTypeComma:
+		return 99
+
+	case lexer.LexemTypeAssignment:
+		return 90
+
+	case lexer.LexemTypeColon,
+		lexer.LexemTypeQuestion:
+		return 82
+
+	// Use here binary operator priorities.
+
+	case lexer.LexemTypeDisjunction:
+		return 80
+	case lexer.LexemTypeConjunction:
+		return 79
+
+	case lexer.LexemTypeOr:
+		return 78
+	case lexer.LexemTypeXor:
+		return 77
+	case lexer.LexemTypeAnd:
+		return 76
+
+	case lexer.LexemTypeCompareEqual,
+		lexer.LexemTypeCompareNotEqual:
+		return 75
+
+	case lexer.LexemTypeCompareLess,
+		lexer.LexemTypeCompareLessOrEqual,
+		lexer.LexemTypeCompareGreater,
+		lexer.LexemTypeCompareGreaterOrEqual:
+		return 74
+
+	case lexer.LexemTypeCompareOrder:
+		return 73
+
+	case lexer.LexemTypeShiftLeft,
+		lexer.LexemTypeShiftRight:
+		return 72
+
+	case lexer.LexemTypePlus:
+		return 71
+	case lexer.LexemTypeMinus: // TODO - what about unary minus?
+		return 70
+
+	case lexer.LexemTypeStar,
+		lexer.LexemTypeSlash,
+		lexer.LexemTypePercent:
+		return 69
+
+	case lexer.LexemTypeDot:
+		return 40
+
+	case lexer.LexemTypeBraceLeft:
+		return 30
+
+	case lexer.LexemTypeBracketLeft,
+		lexer.LexemTypeSquareBracketLeft,
+		lexer.LexemTypeTemplateBracketLeft,
+		lexer.LexemTypeMacroBracketLeft:
+		return 20
+
+	case lexer.LexemTypeIdentifier:
+		return 10
+
+		// TODO - add other lexems
+	}
+
+	return 1
+}
+
+// Returns true for lexems like "name:expr" or "?e:expr", created by joinMacroParameters.
+func isJoinedMacroMatcherElement(lexem *lexer.Lexem) bool {
+	return (lexem.Type == lexer.LexemTypeIdentifier || lexem.Type == lexer.LexemTypeMacroIdentifier) && strings.Contains(lexem.Text, ":")
+}
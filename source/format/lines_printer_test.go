@@ -0,0 +1,62 @@
+package format
+
+import (
+	"testing"
+)
+
+func TestMultilineCommentsPrinting(t *testing.T) {
+	test_cases := []struct {
+		program              string
+		indentation_sequence string
+		line_end_sequence    string
+		expected             string
+	}{
+		{
+			// Comment is re-indented together with surrounding code.
+			"fn f()\n{\n/* a\n   b\n*/\nx;\n}\n",
+			"\t", "\n",
+			"fn f()\n{\n\t/* a\n\t   b\n\t*/\n\tx;\n}\n",
+		},
+		{
+			"fn f()\n{\n\t\t/* a\n\t\t   b\n\t\t*/\n\t\tx;\n}\n",
+			"  ", "\n",
+			"fn f()\n{\n  /* a\n     b\n  */\n  x;\n}\n",
+		},
+		{
+			// Empty comment lines are not indented.
+			"fn f()\n{\n\t/* a\n\n\t*/\n\tx;\n}\n",
+			"\t", "\n",
+			"fn f()\n{\n\t/* a\n\n\t*/\n\tx;\n}\n",
+		},
+		{
+			"fn f()\n{\n\t/* a /* nested\n\t*/ */\n\tx;\n}\n",
+			"\t", "\n",
+			"fn f()\n{\n\t/* a /* nested\n\t*/ */\n\tx;\n}\n",
+		},
+		{
+			// Configured line end is used inside comments.
+			"fn f()\n{\n\t/* a\n\t   b */\n\tx;\n}\n",
+			"\t", "\r\n",
+			"fn f()\r\n{\r\n\t/* a\r\n\t   b */\r\n\tx;\r\n}\r\n",
+		},
+		{
+			// CRLF line ends of the source are not kept.
+			"fn f()\r\n{\r\n\t/* a\r\n\t   b */\r\n\tx;\r\n}\r\n",
+			"\t", "\n",
+			"fn f()\n{\n\t/* a\n\t   b */\n\tx;\n}\n",
+		},
+	}
+
+	for _, test_case := range test_cases {
+		options := GetDefaultOptions()
+		options.IndentationSequence = test_case.indentation_sequence
+		options.LineEndSequence = test_case.line_end_sequence
+
+		result, err := Source([]byte(test_case.program), options)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test_case.program, err)
+		} else if string(result) != test_case.expected {
+			t.Errorf("%q: got %q, expected %q", test_case.program, result, test_case.expected)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+)
+
+// Join macro parameters like ?e:expr in matchers of macro definitions into single lexems,
+// so that they are printed without spaces and never splitted.
+// Matcher head like name:expr is joined too.
+// Matcher is the first <? ?> block after "?macro".
+func joinMacroParameters(lexems []lexer.Lexem) []lexer.Lexem {
+
+	result := make([]lexer.Lexem, 0, len(lexems))
+
+	matcher_depth := 0
+	for i := 0; i < len(lexems); i++ {
+		lexem := lexems[i]
+
+		if lexem.Type == lexer.LexemTypeMacroBracketLeft {
+			if matcher_depth > 0 {
+				matcher_depth++ // Nested block, like in ?e:rep<? ?>.
+			} else if i > 0 && lexems[i-1].Type == lexer.LexemTypeMacroIdentifier && lexems[i-1].Text == "?macro" {
+				matcher_depth = 1
+
+				if i+3 < len(lexems) &&
+					lexems[i+1].Type == lexer.LexemTypeIdentifier &&
+					lexems[i+2].Type == lexer.LexemTypeColon &&
+					lexems[i+3].Type == lexer.LexemTypeIdentifier {
+
+					head := lexems[i+1]
+					head.Text = head.Text + lexems[i+2].Text + lexems[i+3].Text
+					head.Span.End = lexems[i+3].Span.End
+
+					result = append(result, lexem, head)
+					i += 3
+					continue
+				}
+			}
+		} else if lexem.Type == lexer.LexemTypeMacroBracketRight {
+			if matcher_depth > 0 {
+				matcher_depth--
+			}
+		} else if matcher_depth > 0 &&
+			lexem.Type == lexer.LexemTypeMacroIdentifier &&
+			i+2 < len(lexems) &&
+			lexems[i+1].Type == lexer.LexemTypeColon &&
+			lexems[i+2].Type == lexer.LexemTypeIdentifier {
+
+			lexem.Text = lexem.Text + lexems[i+1].Text + lexems[i+2].Text
+			lexem.Span.End = lexems[i+2].Span.End
+			i += 2
+		}
+
+		result = append(result, lexem)
+	}
+
+	return result
+}
@@ -0,0 +1,38 @@
+package format
+
+import (
+	"testing"
+)
+
+func TestMacroParametersAreNotSpaced(t *testing.T) {
+	test_cases := []struct {
+		program  string
+		expected string
+	}{
+		{
+			"?macro <? m:expr ( ?a:expr ?b:rep<? ?c:ident , ?> ) ?> -> <? select( ?a ? ?b : x ) ?>",
+			"?macro <? m:expr ( ?a:expr ?b:rep <? ?c:ident, ?> ) ?> -> <? select( ?a ? ?b : x ) ?>\n",
+		},
+		{
+			"?macro <? m:block ?e:expr( ) ?> -> <? ?e( ) ?>",
+			"?macro <? m:block ?e:expr () ?> -> <? ?e() ?>\n",
+		},
+		{
+			// Not a macro definition.
+			"auto x = a ? b : c;",
+			"auto x = a ? b : c;\n",
+		},
+	}
+
+	options := GetDefaultOptions()
+	options.MaxLineWidth = 200
+
+	for _, test_case := range test_cases {
+		result, err := Source([]byte(test_case.program), options)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test_case.program, err)
+		} else if string(result) != test_case.expected {
+			t.Errorf("%q: got %q, expected %q", test_case.program, result, test_case.expected)
+		}
+	}
+}
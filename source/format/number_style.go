@@ -0,0 +1,53 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+	"strings"
+)
+
+// Values of number style option.
+const (
+	NumberStylePreserve = "preserve" // Keep numbers as is.
+	NumberStyleLower    = "lower"    // Lowercase hex digits.
+	NumberStyleUpper    = "upper"    // Uppercase hex digits.
+)
+
+// Change texts of number lexems according to number style option.
+func normalizeNumbers(lexems []lexer.Lexem, number_style string) {
+	if number_style == NumberStylePreserve {
+		return
+	}
+
+	for i := range lexems {
+		if lexems[i].Type == lexer.LexemTypeNumber {
+			lexems[i].Text = normalizeNumber(lexems[i].Text, number_style)
+		}
+	}
+}
+
+// Change case of hex digits of given number. Type suffix is not changed.
+func normalizeNumber(number string, number_style string) string {
+	if !strings.HasPrefix(number, "0x") {
+		return number // Not a hex number - nothing to change.
+	}
+
+	// Hex digits end at suffix, which can't start with hex digit.
+	digits_end := 2
+	for digits_end < len(number) {
+		c := number[digits_end]
+		is_hex_digit := (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+		if !is_hex_digit && c != '.' {
+			break
+		}
+		digits_end++
+	}
+
+	digits := number[2:digits_end]
+	if number_style == NumberStyleUpper {
+		digits = strings.ToUpper(digits)
+	} else {
+		digits = strings.ToLower(digits)
+	}
+
+	return number[:2] + digits + number[digits_end:]
+}
@@ -0,0 +1,90 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+	"testing"
+)
+
+func TestNormalizeNumber(t *testing.T) {
+	test_cases := []struct {
+		number string
+		lower  string
+		upper  string
+	}{
+		{"123", "123", "123"},
+		{"1.5e+3f", "1.5e+3f", "1.5e+3f"},
+		{"0b101u", "0b101u", "0b101u"},
+		{"0xAbC", "0xabc", "0xABC"},
+		{"0xABu64", "0xabu64", "0xABu64"},
+		{"0x1.8f", "0x1.8f", "0x1.8F"},
+	}
+
+	for _, test_case := range test_cases {
+		if result := normalizeNumber(test_case.number, NumberStyleLower); result != test_case.lower {
+			t.Errorf("%q: lower is %q, expected %q", test_case.number, result, test_case.lower)
+		}
+		if result := normalizeNumber(test_case.number, NumberStyleUpper); result != test_case.upper {
+			t.Errorf("%q: upper is %q, expected %q", test_case.number, result, test_case.upper)
+		}
+	}
+}
+
+func TestNumberStyleInVerbatimRegions(t *testing.T) {
+	test_cases := []struct {
+		name     string
+		program  string
+		ranges   []LineRange
+		expected string
+	}{
+		{
+			"whole file",
+			"fn f()\n{\n\tvar u32 x = 0xAB;\n\tvar u32 y = 0xCD;\n}\n",
+			nil,
+			"fn f()\n{\n\tvar u32 x = 0xab;\n\tvar u32 y = 0xcd;\n}\n",
+		},
+		{
+			"format off",
+			"fn f()\n{\n\t// format: off\n\tvar u32 x = 0xAB;\n\t// format: on\n\tvar u32 y = 0xCD;\n}\n",
+			nil,
+			"fn f()\n{\n\t// format: off\n\tvar u32 x = 0xAB;\n\t// format: on\n\tvar u32 y = 0xcd;\n}\n",
+		},
+		{
+			"line ranges",
+			"fn f()\n{\n\tvar u32 x = 0xAB;\n\tvar u32 y = 0xCD;\n}\n",
+			[]LineRange{{First: 4, Last: 4}},
+			"fn f()\n{\n\tvar u32 x = 0xAB;\n\tvar u32 y = 0xcd;\n}\n",
+		},
+		{
+			"line ranges with unformatted lines",
+			"fn f()\n{\n\tvar u32 x=0xAB;\n\tvar u32 y=0xCD;\n}\n",
+			[]LineRange{{First: 3, Last: 3}},
+			"fn f()\n{\n\tvar u32 x = 0xab;\n\tvar u32 y=0xCD;\n}\n",
+		},
+	}
+
+	for _, test_case := range test_cases {
+		options := GetDefaultOptions()
+		options.NumberStyle = NumberStyleLower
+
+		result, err := SourceLines([]byte(test_case.program), options, test_case.ranges)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test_case.name, err)
+			continue
+		}
+		if string(result) != test_case.expected {
+			t.Errorf("%s: got %q, expected %q", test_case.name, result, test_case.expected)
+		}
+
+		lexems, err := lexer.SplitProgramIntoLexems(test_case.program)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test_case.name, err)
+			continue
+		}
+		if err := VerifyLexemsPreserved(lexems, string(result), &options); err != nil {
+			t.Errorf("%s: verification failed: %s", test_case.name, err)
+		}
+		if _, err := ComputeWhitespaceEdits(test_case.program, lexems, string(result), &options); err != nil {
+			t.Errorf("%s: edits computation failed: %s", test_case.name, err)
+		}
+	}
+}
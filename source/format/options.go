@@ -0,0 +1,190 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Options struct {
+	IndentationSequence string
+	LineEndSequence     string
+	TabSize             uint
+	MaxLineWidth        uint
+	MaxEmptyLines       uint   // Maximum number of consecutive empty lines to preserve.
+	NumberStyle         string // One of NumberStyle constants.
+}
+
+func GetDefaultOptions() Options {
+	return Options{
+		IndentationSequence: "\t",
+		LineEndSequence:     "\n",
+		TabSize:             4,
+		MaxLineWidth:        60,
+		MaxEmptyLines:       1,
+		NumberStyle:         NumberStylePreserve}
+}
+
+// Named sets of options.
+var StylePresets = map[string]Options{
+	"default": GetDefaultOptions(),
+	// Style of the Ü standard library sources.
+	"ustlib": {
+		IndentationSequence: "\t",
+		LineEndSequence:     "\n",
+		TabSize:             4,
+		MaxLineWidth:        120,
+		MaxEmptyLines:       1,
+		NumberStyle:         NumberStylePreserve},
+	"compact": {
+		IndentationSequence: "  ",
+		LineEndSequence:     "\n",
+		TabSize:             2,
+		MaxLineWidth:        80,
+		MaxEmptyLines:       1,
+		NumberStyle:         NumberStylePreserve},
+	"wide": {
+		IndentationSequence: "\t",
+		LineEndSequence:     "\n",
+		TabSize:             4,
+		MaxLineWidth:        160,
+		MaxEmptyLines:       2,
+		NumberStyle:         NumberStylePreserve},
+}
+
+// Get options for style preset with given name.
+func GetStyleOptions(style string) (Options, error) {
+	options, ok := StylePresets[style]
+	if !ok {
+		names := make([]string, 0, len(StylePresets))
+		for name := range StylePresets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return Options{}, fmt.Errorf("unknown style \"%s\", expected one of: %s", style, strings.Join(names, ", "))
+	}
+	return options, nil
+}
+
+type OptionKind byte
+
+const (
+	OptionKindString OptionKind = iota
+	OptionKindUint
+)
+
+// Description of single formatting option, used for setting options from configuration files and command line.
+type OptionDescriptor struct {
+	Name        string
+	Kind        OptionKind
+	Description string
+	Set         func(options *Options, value string) error
+	Get         func(options *Options) string
+}
+
+var OptionDescriptors = []OptionDescriptor{
+	{
+		Name:        "indentation_sequence",
+		Kind:        OptionKindString,
+		Description: "sequence of tabs or spaces used for single indentation level",
+		Set: func(options *Options, value string) error {
+			if len(value) == 0 || strings.Trim(value, " \t") != "" {
+				return errors.New("indentation sequence should be non-empty and contain only spaces and tabs")
+			}
+			options.IndentationSequence = value
+			return nil
+		},
+		Get: func(options *Options) string {
+			return options.IndentationSequence
+		},
+	},
+	{
+		Name:        "line_end_sequence",
+		Kind:        OptionKindString,
+		Description: "line ending: \"\\n\", \"\\r\\n\" or \"\\r\"",
+		Set: func(options *Options, value string) error {
+			if value != "\n" && value != "\r\n" && value != "\r" {
+				return errors.New("line end sequence should be \"\\n\", \"\\r\\n\" or \"\\r\"")
+			}
+			options.LineEndSequence = value
+			return nil
+		},
+		Get: func(options *Options) string {
+			return options.LineEndSequence
+		},
+	},
+	{
+		Name:        "tab_size",
+		Kind:        OptionKindUint,
+		Description: "width of tab character, used for line width calculation",
+		Set: func(options *Options, value string) error {
+			return parsePositiveUint(value, &options.TabSize)
+		},
+		Get: func(options *Options) string {
+			return strconv.FormatUint(uint64(options.TabSize), 10)
+		},
+	},
+	{
+		Name:        "max_line_width",
+		Kind:        OptionKindUint,
+		Description: "maximum line width, longer lines are splitted",
+		Set: func(options *Options, value string) error {
+			return parsePositiveUint(value, &options.MaxLineWidth)
+		},
+		Get: func(options *Options) string {
+			return strconv.FormatUint(uint64(options.MaxLineWidth), 10)
+		},
+	},
+	{
+		Name:        "max_empty_lines",
+		Kind:        OptionKindUint,
+		Description: "maximum number of consecutive empty lines to preserve",
+		Set: func(options *Options, value string) error {
+			n, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid unsigned integer \"%s\"", value)
+			}
+			options.MaxEmptyLines = uint(n)
+			return nil
+		},
+		Get: func(options *Options) string {
+			return strconv.FormatUint(uint64(options.MaxEmptyLines), 10)
+		},
+	},
+	{
+		Name:        "number_style",
+		Kind:        OptionKindString,
+		Description: "case of hex digits in numbers: \"preserve\", \"lower\" or \"upper\"",
+		Set: func(options *Options, value string) error {
+			if value != NumberStylePreserve && value != NumberStyleLower && value != NumberStyleUpper {
+				return errors.New("number style should be \"preserve\", \"lower\" or \"upper\"")
+			}
+			options.NumberStyle = value
+			return nil
+		},
+		Get: func(options *Options) string {
+			return options.NumberStyle
+		},
+	},
+}
+
+// Returns nil if there is no option with given name.
+func FindOptionDescriptor(name string) *OptionDescriptor {
+	for i := range OptionDescriptors {
+		if OptionDescriptors[i].Name == name {
+			return &OptionDescriptors[i]
+		}
+	}
+	return nil
+}
+
+func parsePositiveUint(value string, out *uint) error {
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || n == 0 {
+		return fmt.Errorf("invalid positive integer \"%s\"", value)
+	}
+	*out = uint(n)
+	return nil
+}
@@ -0,0 +1,84 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+)
+
+// Replacement of a part of source text.
+type TextEdit struct {
+	Offset      uint // In bytes.
+	Length      uint // In bytes.
+	Replacement string
+}
+
+// Compute minimal list of edits, which transform source text into formatted text.
+// Lexems of source and formatted text are aligned, so that edits affect only whitespaces between lexems
+// (and inside multiline comments, which may be re-indented, and numbers, which may be normalized).
+// Returns error if formatted text doesn't contain the same lexems as the source (see VerifyLexemsPreserved).
+func ComputeWhitespaceEdits(source string, source_lexems []lexer.Lexem, formatted string, options *Options) ([]TextEdit, error) {
+
+	formatted_lexems, err := lexFormattedText(formatted)
+	if err != nil {
+		return nil, err
+	}
+
+	err = compareLexems(source_lexems, formatted_lexems, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TextEdit, 0)
+
+	prev_source_end := uint(0)
+	prev_formatted_end := uint(0)
+
+	for i := range source_lexems {
+		source_lexem := &source_lexems[i]
+		formatted_lexem := &formatted_lexems[i]
+
+		// Whitespaces before lexem.
+		appendMinimalTextEdit(
+			&result,
+			prev_source_end,
+			source[prev_source_end:source_lexem.Span.Begin.Offset],
+			formatted[prev_formatted_end:formatted_lexem.Span.Begin.Offset])
+
+		// Lexem itself. It may differ only for multiline comments and numbers.
+		appendMinimalTextEdit(
+			&result,
+			source_lexem.Span.Begin.Offset,
+			source[source_lexem.Span.Begin.Offset:source_lexem.Span.End.Offset],
+			formatted[formatted_lexem.Span.Begin.Offset:formatted_lexem.Span.End.Offset])
+
+		prev_source_end = source_lexem.Span.End.Offset
+		prev_formatted_end = formatted_lexem.Span.End.Offset
+	}
+
+	return result, nil
+}
+
+// Add edit, replacing old text at given offset with new text. Common prefix and suffix are not included.
+func appendMinimalTextEdit(edits *[]TextEdit, offset uint, old_text string, new_text string) {
+
+	prefix := 0
+	for prefix < len(old_text) && prefix < len(new_text) && old_text[prefix] == new_text[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(old_text)-prefix && suffix < len(new_text)-prefix &&
+		old_text[len(old_text)-1-suffix] == new_text[len(new_text)-1-suffix] {
+		suffix++
+	}
+
+	if prefix+suffix == len(old_text) && prefix+suffix == len(new_text) {
+		return // Texts are equal.
+	}
+
+	*edits = append(
+		*edits,
+		TextEdit{
+			Offset:      offset + uint(prefix),
+			Length:      uint(len(old_text) - prefix - suffix),
+			Replacement: new_text[prefix : len(new_text)-suffix]})
+}
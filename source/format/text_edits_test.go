@@ -0,0 +1,120 @@
+package format
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+	"testing"
+)
+
+func TestComputeWhitespaceEdits(t *testing.T) {
+	test_cases := []struct {
+		name    string
+		program string
+		modify  func(options *Options)
+	}{
+		{"formatted", "fn f()\n{\n\tx;\n}\n", nil},
+		{"spaces", "fn  f( )\n{\n\tauto x=a+b ;\n}", nil},
+		{"indentation", "fn f()\n{\nx;\n    y;\n}\n", nil},
+		{"long line", "fn f()\n{\n\tauto x = some_long_function_name( first_argument, second_argument, third_argument );\n}\n", nil},
+		{"crlf source", "fn f()\r\n{\r\n  x;\r\n}\r\n", nil},
+		{"crlf output", "fn f()\n{\n  x;\n}\n", func(options *Options) { options.LineEndSequence = "\r\n" }},
+		{"bom", "\uFEFFfn f()\n{\n  x;\n}\n", nil},
+		{"multiline comment re-indent", "fn f()\n{\n/* a\n   b\n*/\nx;\n}\n", nil},
+		{"multiline comment crlf", "fn f()\r\n{\r\n\t\t/* a\r\n\t\t   b */\r\n\t\tx;\r\n}\r\n", nil},
+		{"number normalization", "fn f()\n{\n  x= 0xAbC + 0X1 + 0b101u + 1.5e+3f;\n}\n", func(options *Options) { options.NumberStyle = NumberStyleLower }},
+		{"number normalization upper", "auto x = 0xabc;", func(options *Options) { options.NumberStyle = NumberStyleUpper }},
+		{"format off", "fn f()\n{\n// format: off\n  x  ;\n// format: on\n  y  ;\n}\n", nil},
+		{"unicode", "fn f()\n{\n  auto ж = \"ё\"  ;\n}\n", nil},
+	}
+
+	for _, test_case := range test_cases {
+		options := GetDefaultOptions()
+		if test_case.modify != nil {
+			test_case.modify(&options)
+		}
+
+		formatted, err := Source([]byte(test_case.program), options)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test_case.name, err)
+			continue
+		}
+
+		lexems, err := lexer.SplitProgramIntoLexems(test_case.program)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test_case.name, err)
+			continue
+		}
+
+		edits, err := ComputeWhitespaceEdits(test_case.program, lexems, string(formatted), &options)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test_case.name, err)
+			continue
+		}
+
+		// Edits should be sorted and should not overlap.
+		result := ""
+		prev_end := uint(0)
+		for _, edit := range edits {
+			if edit.Offset < prev_end {
+				t.Errorf("%s: edit %+v overlaps previous edit", test_case.name, edit)
+				break
+			}
+			result += test_case.program[prev_end:edit.Offset] + edit.Replacement
+			prev_end = edit.Offset + edit.Length
+		}
+		result += test_case.program[prev_end:]
+
+		if result != string(formatted) {
+			t.Errorf("%s: edits produce %q, expected %q", test_case.name, result, formatted)
+		}
+
+		// Edits should touch only whitespaces between lexems, or be inside comments and numbers, which may change.
+		for _, edit := range edits {
+			for _, lexem := range lexems {
+				if lexem.Type == lexer.LexemTypeMultilineComment || lexem.Type == lexer.LexemTypeNumber {
+					inside := edit.Offset >= lexem.Span.Begin.Offset && edit.Offset+edit.Length <= lexem.Span.End.Offset
+					outside := edit.Offset+edit.Length <= lexem.Span.Begin.Offset || edit.Offset >= lexem.Span.End.Offset
+					if !inside && !outside {
+						t.Errorf("%s: edit %+v crosses bounds of lexem %q", test_case.name, edit, lexem.Text)
+					}
+				} else if edit.Offset < lexem.Span.End.Offset && edit.Offset+edit.Length > lexem.Span.Begin.Offset ||
+					edit.Offset > lexem.Span.Begin.Offset && edit.Offset < lexem.Span.End.Offset {
+					t.Errorf("%s: edit %+v touches lexem %q", test_case.name, edit, lexem.Text)
+				}
+			}
+		}
+	}
+}
+
+func TestComputeWhitespaceEditsErrors(t *testing.T) {
+	test_cases := []struct {
+		program      string
+		formatted    string
+		number_style string
+		error        string
+	}{
+		{"a b", "a", NumberStylePreserve, "1:3: formatting dropped lexem \"b\""},
+		{"a b", "a b c", NumberStylePreserve, "1:5: formatting added lexem \"c\" at 1:5 of formatted text"},
+		{"a b", "a c", NumberStylePreserve, "1:3: formatting changed lexem \"b\" into \"c\" at 1:3 of formatted text"},
+		{"a = 0xAB;", "a = 0xab;", NumberStylePreserve, "1:5: formatting changed lexem \"0xAB\" into \"0xab\" at 1:5 of formatted text"},
+		{"a = 0xAb;", "a = 0xAB;", NumberStyleLower, "1:5: formatting changed lexem \"0xab\" (normalized from \"0xAb\") into \"0xAB\" at 1:5 of formatted text"},
+		{"a = 0xab;", "a = 0xAB;", NumberStyleLower, "1:5: formatting changed lexem \"0xab\" into \"0xAB\" at 1:5 of formatted text"},
+		{"a b", "a \"b", NumberStylePreserve, "1:3: formatted text can not be lexed: Unterminated string literal"},
+	}
+
+	for _, test_case := range test_cases {
+		lexems, err := lexer.SplitProgramIntoLexems(test_case.program)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test_case.program, err)
+			continue
+		}
+
+		options := GetDefaultOptions()
+		options.NumberStyle = test_case.number_style
+		_, err = ComputeWhitespaceEdits(test_case.program, lexems, test_case.formatted, &options)
+		if err == nil {
+			t.Errorf("%q -> %q: expected error", test_case.program, test_case.formatted)
+		} else if err.Error() != test_case.error {
+			t.Errorf("%q -> %q: error is %q, expected %q", test_case.program, test_case.formatted, err.Error(), test_case.error)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package format
+
+import (
+	"sort"
+)
+
+// Range of source text (in bytes), which is copied as is.
+type verbatimRegion struct {
+	begin int
+	end   int
+}
+
+// Replace lines with lexems inside given regions with verbatim source text of these regions.
+// Empty lines around verbatim regions are removed, since source text of regions includes all necessary newlines.
+func applyVerbatimRegions(lines []LogicalLine, regions []verbatimRegion, source string) []LogicalLine {
+
+	if len(regions) == 0 {
+		return lines
+	}
+
+	regions = mergeVerbatimRegions(regions)
+
+	result := make([]LogicalLine, 0, len(lines))
+	pending_empty_lines := make([]LogicalLine, 0)
+	after_verbatim_region := false
+	num_emitted_regions := 0
+	next_region := 0
+
+	emit_region := func(region_index int) {
+		if region_index < num_emitted_regions {
+			return
+		}
+		region := regions[region_index]
+		pending_empty_lines = pending_empty_lines[:0]
+		result = append(result, LogicalLine{Verbatim: source[region.begin:region.end]})
+		after_verbatim_region = true
+		num_emitted_regions = region_index + 1
+	}
+
+	for _, line := range lines {
+
+		if len(line.Lexems) == 0 {
+			if !after_verbatim_region {
+				pending_empty_lines = append(pending_empty_lines, line)
+			}
+			continue
+		}
+
+		// Emit regions starting before this line.
+		first_offset := int(line.Lexems[0].Span.Begin.Offset)
+		for next_region < len(regions) && regions[next_region].begin <= first_offset {
+			emit_region(next_region)
+			if regions[next_region].end <= first_offset {
+				next_region++
+			} else {
+				break
+			}
+		}
+
+		// Remove lexems inside current region.
+		line_filtered := LogicalLine{Indentation: line.Indentation}
+		for _, lexem := range line.Lexems {
+			offset := int(lexem.Span.Begin.Offset)
+			if next_region < len(regions) && offset >= regions[next_region].begin && offset < regions[next_region].end {
+				continue
+			}
+			line_filtered.Lexems = append(line_filtered.Lexems, lexem)
+		}
+
+		if len(line_filtered.Lexems) == 0 {
+			continue
+		}
+
+		result = append(result, pending_empty_lines...)
+		pending_empty_lines = pending_empty_lines[:0]
+		result = append(result, line_filtered)
+		after_verbatim_region = false
+	}
+
+	for i := range regions {
+		emit_region(i)
+	}
+
+	return result
+}
+
+// Sort regions and merge overlapping or adjacent regions.
+func mergeVerbatimRegions(regions []verbatimRegion) []verbatimRegion {
+
+	sorted := append([]verbatimRegion(nil), regions...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].begin < sorted[j].begin })
+
+	result := make([]verbatimRegion, 0, len(sorted))
+	for _, region := range sorted {
+		if region.end <= region.begin {
+			continue
+		}
+		if len(result) > 0 && region.begin <= result[len(result)-1].end {
+			result[len(result)-1].end = max(result[len(result)-1].end, region.end)
+		} else {
+			result = append(result, region)
+		}
+	}
+
+	return result
+}
@@ -1,3 +1,3 @@
-module formatter
+module github.com/Panzerschrek/U-00DC-Formatter/source
 
 go 1.22.3
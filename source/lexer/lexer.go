@@ -0,0 +1,809 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Lexem struct {
+	Type           LexemType
+	Text           string
+	Span           SrcSpan
+	NewlinesBefore uint // Number of newlines between previous lexem and this lexem.
+}
+
+// Position in source text.
+type SrcPos struct {
+	Offset uint // In bytes.
+	Line   uint // Starting from 1.
+	Column uint // In characters, starting from 1.
+}
+
+// Range of source text, end position is exclusive.
+type SrcSpan struct {
+	Begin SrcPos
+	End   SrcPos
+}
+
+// Error with position in source text.
+type SrcError struct {
+	Pos  SrcPos
+	Text string
+}
+
+func (e *SrcError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Pos.Line, e.Pos.Column, e.Text)
+}
+
+func NewSrcError(pos SrcPos, format string, args ...any) *SrcError {
+	return &SrcError{Pos: pos, Text: fmt.Sprintf(format, args...)}
+}
+
+type LexemType byte
+
+const (
+	LexemTypeNone LexemType = iota
+
+	LexemTypeLineComment
+	LexemTypeMultilineComment
+
+	LexemTypeIdentifier
+	LexemTypeMacroIdentifier
+	LexemTypeMacroUniqueIdentifier
+	LexemTypeString
+	LexemTypeNumber
+
+	LexemTypeLiteralSuffix // For strings, numbers
+
+	LexemTypeBracketLeft  // (
+	LexemTypeBracketRight // )
+
+	LexemTypeSquareBracketLeft  // [
+	LexemTypeSquareBracketRight // ]
+
+	LexemTypeBraceLeft  // {
+	LexemTypeBraceRight // }
+
+	LexemTypeTemplateBracketLeft  // </
+	LexemTypeTemplateBracketRight // />
+
+	LexemTypeMacroBracketLeft  // <?
+	LexemTypeMacroBracketRight // ?>
+
+	LexemTypeScope // ::
+
+	LexemTypeComma     // ,
+	LexemTypeDot       // .
+	LexemTypeColon     // :
+	LexemTypeSemicolon // ;
+	LexemTypeQuestion  // ?
+
+	LexemTypeAssignment // =
+	LexemTypePlus       // +
+	LexemTypeMinus      // -
+	LexemTypeStar       // *
+	LexemTypeSlash      // /
+	LexemTypePercent    // %
+
+	LexemTypeAnd   // &
+	LexemTypeOr    // |
+	LexemTypeXor   // ^
+	LexemTypeTilda // ~
+	LexemTypeNot   // !
+
+	LexemTypeApostrophe // '
+	LexemTypeAt         // @
+
+	LexemTypeIncrement // ++
+	LexemTypeDecrement // --
+
+	LexemTypeCompareLess           // <
+	LexemTypeCompareGreater        // >
+	LexemTypeCompareEqual          // ==
+	LexemTypeCompareNotEqual       // !=
+	LexemTypeCompareLessOrEqual    // <=
+	LexemTypeCompareGreaterOrEqual // >=
+	LexemTypeCompareOrder          // <=>
+
+	LexemTypeConjunction // &&
+	LexemTypeDisjunction // ||
+
+	LexemTypeAssignAdd // +=
+	LexemTypeAssignSub // -=
+	LexemTypeAssignMul // *=
+	LexemTypeAssignDiv // /=
+	LexemTypeAssignRem // %=
+	LexemTypeAssignAnd // &=
+	LexemTypeAssignOr  // |=
+	LexemTypeAssignXor // ^=
+
+	LexemTypeShiftLeft  // <<
+	LexemTypeShiftRight // >>
+
+	LexemTypeAssignShiftLeft  // <<=
+	LexemTypeAssignShiftRight // >>=
+
+	LexemTypeRightArrow // ->
+
+	LexemTypePointerTypeMark    // $
+	LexemTypeReferenceToPointer // $<
+	LexemTypePointerToReference // $>
+
+	LexemTypeEllipsis // ...
+
+	LexemTypeEndOfFile
+)
+
+func SplitProgramIntoLexems(s string) ([]Lexem, error) {
+	result := make([]Lexem, 0)
+
+	program := s
+	pos := SrcPos{Offset: 0, Line: 1, Column: 1}
+	if strings.HasPrefix(s, ByteOrderMark) {
+		// Skip BOM without counting it as column.
+		s = s[len(ByteOrderMark):]
+		pos.Offset = uint(len(ByteOrderMark))
+	}
+	prev_lexem_end := pos
+
+	for len(s) > 0 {
+
+		// Update position, based on text consumed in previous iteration.
+		pos = advanceSrcPos(program, pos, len(program)-len(s))
+		num_lexems := len(result)
+
+		c, c_size := utf8.DecodeRuneInString(s)
+
+		if IsWhitespace(c) {
+
+			s = s[c_size:] // Skip whitespaces.
+
+		} else if c == '/' && len(s) > c_size && s[1] == '/' {
+
+			// Line comment
+			comment := Lexem{Type: LexemTypeLineComment}
+			s_before := s
+
+			for len(s) > 0 {
+				c, c_size := utf8.DecodeRuneInString(s)
+
+				if IsNewline(c) {
+					break
+				}
+				s = s[c_size:]
+			}
+
+			comment.Text = string(s_before[:len(s_before)-len(s)])
+			result = append(result, comment)
+
+		} else if c == '/' && len(s) > c_size && s[1] == '*' {
+
+			// Multiline comment.
+			line_indentation := getLineIndentation(program, len(program)-len(s))
+
+			comment, err := parseMultilineComment(&s, line_indentation)
+			if err != nil {
+				return nil, NewSrcError(pos, "%s", err.Error())
+			}
+			result = append(result, comment)
+
+		} else if IsIdentifierStartChar(c) {
+
+			result = append(result, parseIdentifier(&s))
+
+		} else if c == '?' && isMacroIdentifier(s) {
+
+			result = append(result, parseMacroIdentifier(&s))
+
+		} else if IsNumberStartChar(c) {
+
+			lexem, err := parseNumber(&s)
+			if err != nil {
+				return nil, NewSrcError(advanceSrcPos(program, pos, len(program)-len(s)), "%s", err.Error())
+			}
+			result = append(result, lexem)
+
+		} else if c == '"' {
+
+			lexem, err := parseString(&s, '"')
+			if err != nil {
+				return nil, NewSrcError(advanceSrcPos(program, pos, len(program)-len(s)), "%s", err.Error())
+			}
+			result = append(result, lexem)
+
+		} else if c == '\'' && isCharLiteral(s) {
+
+			lexem, err := parseString(&s, '\'')
+			if err != nil {
+				return nil, NewSrcError(advanceSrcPos(program, pos, len(program)-len(s)), "%s", err.Error())
+			}
+			result = append(result, lexem)
+
+		} else {
+			// Process fixed lexems.
+			lexem := parseFixedLexem(&s)
+			if lexem.Type == LexemTypeNone {
+				return nil, NewSrcError(pos, "%s", describeUnexpectedCharacter(c))
+			}
+			result = append(result, lexem)
+		}
+
+		if len(result) > num_lexems {
+			// Set span for new lexem.
+			lexem := &result[len(result)-1]
+			lexem.Span.Begin = pos
+			lexem.Span.End = advanceSrcPos(program, pos, len(program)-len(s))
+			lexem.NewlinesBefore = pos.Line - prev_lexem_end.Line
+			prev_lexem_end = lexem.Span.End
+		}
+	}
+
+	pos = advanceSrcPos(program, pos, len(program))
+	result = append(
+		result,
+		Lexem{
+			Type:           LexemTypeEndOfFile,
+			Span:           SrcSpan{Begin: pos, End: pos},
+			NewlinesBefore: pos.Line - prev_lexem_end.Line})
+
+	return result, nil
+}
+
+// Calculate position of given offset in program text, starting from given position.
+func advanceSrcPos(program string, pos SrcPos, offset int) SrcPos {
+	for i, c := range program[pos.Offset:offset] {
+		if IsNewline(c) {
+			next_offset := int(pos.Offset) + i + 1
+			if c == '\r' && next_offset < len(program) && program[next_offset] == '\n' {
+				// Count "\r\n" as single newline.
+			} else {
+				pos.Line++
+				pos.Column = 1
+			}
+		} else {
+			pos.Column++
+		}
+	}
+
+	pos.Offset = uint(offset)
+
+	return pos
+}
+
+// Get offsets of starts of all lines in program text, using the same newlines as for calculation of lexems positions.
+// Element i contains offset of line i + 1.
+func GetLineStartOffsets(program string) []uint {
+	result := []uint{0}
+	for i, c := range program {
+		if IsNewline(c) {
+			if c == '\r' && i+1 < len(program) && program[i+1] == '\n' {
+				continue // Count "\r\n" as single newline.
+			}
+			result = append(result, uint(i+utf8.RuneLen(c)))
+		}
+	}
+	return result
+}
+
+func IsWhitespace(c rune) bool {
+	return c == ' ' || c == '\f' || c == '\n' || c == '\r' || c == '\t' || c == '\v' || c <= 0x1F || c == 0x7F
+}
+
+func IsNewline(c rune) bool {
+	// Unicode newlines (next line, line separator, paragraph separator) are not counted,
+	// since they aren't whitespaces and are rejected outside comments and strings.
+	return c == '\n' || // line feed
+		c == '\r' || // carriage return
+		c == '\f' || // form feed
+		c == '\v' // vertical tab
+}
+
+// Get whitespace prefix of the line, containing given offset.
+func getLineIndentation(program string, offset int) string {
+	line_start := offset
+	for line_start > 0 && program[line_start-1] != '\n' && program[line_start-1] != '\r' {
+		line_start--
+	}
+
+	line_end := line_start
+	for line_end < offset && (program[line_end] == ' ' || program[line_end] == '\t') {
+		line_end++
+	}
+
+	return program[line_start:line_end]
+}
+
+// Letters of all scripts, like Unicode XID_Start.
+func IsIdentifierStartChar(c rune) bool {
+	if c < utf8.RuneSelf {
+		return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	}
+	return (unicode.IsLetter(c) || unicode.In(c, unicode.Nl, unicode.Other_ID_Start)) &&
+		!unicode.In(c, unicode.Pattern_Syntax, unicode.Pattern_White_Space)
+}
+
+// Letters, digits, combining marks and connectors, like Unicode XID_Continue.
+func IsIdentifierChar(c rune) bool {
+	if c < utf8.RuneSelf {
+		return IsIdentifierStartChar(c) || IsNumberStartChar(c) || c == '_'
+	}
+	return IsIdentifierStartChar(c) ||
+		(unicode.In(c, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc, unicode.Other_ID_Continue) &&
+			!unicode.In(c, unicode.Pattern_Syntax, unicode.Pattern_White_Space))
+}
+
+func IsNumberStartChar(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+func parseIdentifier(s *string) Lexem {
+
+	s_initial := *s
+
+	for len(*s) > 0 {
+		c, c_size := utf8.DecodeRuneInString(*s)
+		if !IsIdentifierChar(c) {
+			break
+		}
+
+		*s = (*s)[c_size:]
+	}
+
+	return Lexem{Type: LexemTypeIdentifier, Text: string(s_initial[:len(s_initial)-len(*s)])}
+}
+
+// Check if text starts with macro identifier like ?name or unique macro identifier like ??name.
+func isMacroIdentifier(s string) bool {
+	if strings.HasPrefix(s, "??") {
+		s = s[2:]
+	} else {
+		s = s[1:]
+	}
+	c, _ := utf8.DecodeRuneInString(s)
+	return IsIdentifierStartChar(c)
+}
+
+func parseMacroIdentifier(s *string) Lexem {
+	lexem_type := LexemTypeMacroIdentifier
+	prefix := "?"
+	if strings.HasPrefix(*s, "??") {
+		lexem_type = LexemTypeMacroUniqueIdentifier
+		prefix = "??"
+	}
+	*s = (*s)[len(prefix):]
+
+	identifier := parseIdentifier(s)
+
+	return Lexem{Type: lexem_type, Text: prefix + identifier.Text}
+}
+
+// Parse number like 123, 0x7Fu8, 0b1.01e-3, 1.5e+3f32.
+// Grammar is the same as in the Ü compiler lexer: optional lowercase base prefix ("0b", "0o", "0x"),
+// integer part, optional fractional part, optional exponent with optional sign and decimal digits, optional type suffix.
+// Exponent is not possible for hex numbers, since "e" is a hex digit.
+// On error given string points to erroneous place.
+func parseNumber(s *string) (Lexem, error) {
+
+	s_initial := *s
+
+	base := 10
+	if len(*s) >= 2 && (*s)[0] == '0' {
+		switch (*s)[1] {
+		case 'b':
+			base = 2
+		case 'o':
+			base = 8
+		case 'x':
+			base = 16
+		}
+		if base != 10 {
+			*s = (*s)[2:]
+		}
+	}
+
+	// Parse integer part.
+	if !skipNumberDigits(s, base) {
+		*s = s_initial
+		return Lexem{}, errors.New("Expected digits after number prefix")
+	}
+
+	if len(*s) >= 2 && (*s)[0] == '.' && getDigitValue(rune((*s)[1])) < base {
+		// Parse fractional part.
+		*s = (*s)[1:]
+		skipNumberDigits(s, base)
+	}
+
+	if base != 16 && strings.HasPrefix(*s, "e") {
+		// Parse exponent.
+		exponent := (*s)[1:]
+		has_sign := strings.HasPrefix(exponent, "+") || strings.HasPrefix(exponent, "-")
+		if has_sign {
+			exponent = exponent[1:]
+		}
+
+		if skipNumberDigits(&exponent, 10) {
+			*s = exponent
+		} else if has_sign {
+			*s = (*s)[2:]
+			return Lexem{}, errors.New("Expected exponent digits")
+		}
+		// Otherwise this is start of a type suffix.
+	}
+
+	c, _ := utf8.DecodeRuneInString(*s)
+	if IsIdentifierStartChar(c) {
+		// Type suffix.
+		parseIdentifier(s)
+	} else if getDigitValue(c) < 10 {
+		return Lexem{}, fmt.Errorf("Invalid digit for number with base %d", base)
+	}
+
+	return Lexem{Type: LexemTypeNumber, Text: string(s_initial[:len(s_initial)-len(*s)])}, nil
+}
+
+// Skip digits of given base. Returns false if there are no digits.
+func skipNumberDigits(s *string, base int) bool {
+	if len(*s) == 0 || getDigitValue(rune((*s)[0])) >= base {
+		return false
+	}
+
+	for len(*s) > 0 && getDigitValue(rune((*s)[0])) < base {
+		*s = (*s)[1:]
+	}
+
+	return true
+}
+
+// Returns value greater than any base for non-digits.
+func getDigitValue(c rune) int {
+	if c >= '0' && c <= '9' {
+		return int(c - '0')
+	}
+	if c >= 'a' && c <= 'f' {
+		return int(c-'a') + 10
+	}
+	if c >= 'A' && c <= 'F' {
+		return int(c-'A') + 10
+	}
+	return 16
+}
+
+// Parse string literal like "abc" or character literal like 'a', with optional suffix.
+// On error given string points to erroneous place.
+func parseString(s *string, quote rune) (Lexem, error) {
+
+	s_initial := *s
+
+	*s = (*s)[1:] // Skip initial quote
+
+	for {
+		if len(*s) == 0 || *s == "\\" {
+			*s = s_initial
+			return Lexem{}, errors.New("Unterminated string literal")
+		}
+
+		c, c_size := utf8.DecodeRuneInString(*s)
+		if c == '\\' {
+			err := skipEscapeSequence(s)
+			if err != nil {
+				return Lexem{}, err
+			}
+		} else if c == quote {
+			*s = (*s)[1:]
+			break
+		} else {
+			*s = (*s)[c_size:]
+		}
+	}
+
+	c, _ := utf8.DecodeRuneInString(*s)
+	if IsIdentifierStartChar(c) {
+		// Literal suffix, like "abc"u16 or "a"c8. Keep it together with the literal.
+		parseIdentifier(s)
+	}
+
+	return Lexem{Type: LexemTypeString, Text: string(s_initial[:len(s_initial)-len(*s)])}, nil
+}
+
+// Skip escape sequence like \n or \u0041, starting with backslash.
+// On error given string remains unchanged.
+func skipEscapeSequence(s *string) error {
+
+	sequence := (*s)[1:] // Skip backslash.
+
+	c, c_size := utf8.DecodeRuneInString(sequence)
+	sequence = sequence[c_size:]
+
+	switch c {
+	case '"', '\'', '\\', '/', '0', 'b', 'f', 'n', 'r', 't':
+
+	case 'u':
+		// Exactly 4 hex digits of code point.
+		for i := 0; i < 4; i++ {
+			if len(sequence) == 0 || getDigitValue(rune(sequence[0])) >= 16 {
+				return errors.New("Expected 4 hex digits in escape sequence \\u")
+			}
+			sequence = sequence[1:]
+		}
+
+	default:
+		if c_size == 0 || IsNewline(c) {
+			return errors.New("Unfinished escape sequence at end of line")
+		}
+		return fmt.Errorf("Invalid escape sequence \\%s", string(c))
+	}
+
+	*s = sequence
+	return nil
+}
+
+// Check if text starts with character literal like 'a' or '\n'.
+// Apostrophes not forming such literal are used in reference notation.
+func isCharLiteral(s string) bool {
+
+	s = s[1:] // Skip initial '
+
+	c, c_size := utf8.DecodeRuneInString(s)
+	if c == utf8.RuneError || IsNewline(c) {
+		return false
+	}
+
+	if c == '\\' {
+		// Escape sequence - backslash, any char, and possible hex digits of code.
+		s = s[c_size:]
+		c, c_size = utf8.DecodeRuneInString(s)
+		if c == utf8.RuneError || IsNewline(c) {
+			return false
+		}
+		s = s[c_size:]
+
+		for len(s) > 0 {
+			c, c_size := utf8.DecodeRuneInString(s)
+			if !IsIdentifierChar(c) {
+				break
+			}
+			s = s[c_size:]
+		}
+	} else if c == '\'' {
+		return false
+	} else {
+		s = s[c_size:]
+	}
+
+	return strings.HasPrefix(s, "'")
+}
+
+// Parse comment like /* some text */.
+// Nested comments are supported.
+// Indentation of the line where the comment starts is removed from all following comment lines,
+// in order to allow re-indentation of the comment in the printer.
+// Line endings inside the comment are normalized to "\n".
+func parseMultilineComment(s *string, line_indentation string) (Lexem, error) {
+
+	s_initial := *s
+
+	*s = (*s)[2:] // Skip initial /*
+
+	depth := 1
+	for depth > 0 {
+		if len(*s) == 0 {
+			return Lexem{}, errors.New("Unterminated multiline comment")
+		}
+
+		if strings.HasPrefix(*s, "/*") {
+			depth++
+			*s = (*s)[2:]
+		} else if strings.HasPrefix(*s, "*/") {
+			depth--
+			*s = (*s)[2:]
+		} else {
+			_, c_size := utf8.DecodeRuneInString(*s)
+			*s = (*s)[c_size:]
+		}
+	}
+
+	text := string(s_initial[:len(s_initial)-len(*s)])
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = removeIndentationPrefix(lines[i], line_indentation)
+	}
+
+	return Lexem{Type: LexemTypeMultilineComment, Text: strings.Join(lines, "\n")}, nil
+}
+
+// Remove common part of given indentation and leading whitespaces of given line.
+func removeIndentationPrefix(line string, indentation string) string {
+	i := 0
+	for i < len(line) && i < len(indentation) && line[i] == indentation[i] {
+		i++
+	}
+	return line[i:]
+}
+
+// Returns lexem with type LexemTypeNone if there is no fixed lexem at start of given string.
+func parseFixedLexem(s *string) Lexem {
+
+	if len(*s) >= 3 { // Fixed lexems of length 3.
+		lexem_type := textToLexem3((*s)[0:3])
+		if lexem_type != LexemTypeNone {
+			lexem := Lexem{Text: (*s)[0:3], Type: lexem_type}
+			*s = (*s)[3:]
+			return lexem
+		}
+	}
+	if len(*s) >= 2 { // Fixed lexems of length 2.
+		lexem_type := textToLexem2((*s)[0:2])
+		if lexem_type != LexemTypeNone {
+			lexem := Lexem{Text: (*s)[0:2], Type: lexem_type}
+			*s = (*s)[2:]
+			return lexem
+		}
+	}
+	if len(*s) >= 1 { // Fixed lexems of length 1.
+		lexem_type := textToLexem1((*s)[0:1])
+		if lexem_type != LexemTypeNone {
+			lexem := Lexem{Text: (*s)[0:1], Type: lexem_type}
+			*s = (*s)[1:]
+			return lexem
+		}
+	}
+
+	// None of the fixed lexems.
+	return Lexem{Type: LexemTypeNone}
+}
+
+func textToLexem1(s string) LexemType {
+	switch s {
+	case "(":
+		return LexemTypeBracketLeft
+	case ")":
+		return LexemTypeBracketRight
+	case "[":
+		return LexemTypeSquareBracketLeft
+	case "]":
+		return LexemTypeSquareBracketRight
+	case "{":
+		return LexemTypeBraceLeft
+	case "}":
+		return LexemTypeBraceRight
+
+	case ",":
+		return LexemTypeComma
+	case ".":
+		return LexemTypeDot
+	case ":":
+		return LexemTypeColon
+	case ";":
+		return LexemTypeSemicolon
+	case "?":
+		return LexemTypeQuestion
+
+	case "=":
+		return LexemTypeAssignment
+	case "+":
+		return LexemTypePlus
+	case "-":
+		return LexemTypeMinus
+	case "*":
+		return LexemTypeStar
+	case "/":
+		return LexemTypeSlash
+	case "%":
+		return LexemTypePercent
+
+	case "<":
+		return LexemTypeCompareLess
+	case ">":
+		return LexemTypeCompareGreater
+
+	case "&":
+		return LexemTypeAnd
+	case "|":
+		return LexemTypeOr
+	case "^":
+		return LexemTypeXor
+	case "~":
+		return LexemTypeTilda
+	case "!":
+		return LexemTypeNot
+
+	case "'":
+		return LexemTypeApostrophe
+	case "@":
+		return LexemTypeAt
+
+	case "$":
+		return LexemTypePointerTypeMark
+	}
+
+	return LexemTypeNone
+}
+
+func textToLexem2(s string) LexemType {
+	switch s {
+	case "</":
+		return LexemTypeTemplateBracketLeft
+	case "/>":
+		return LexemTypeTemplateBracketRight
+
+	case "<?":
+		return LexemTypeMacroBracketLeft
+	case "?>":
+		return LexemTypeMacroBracketRight
+
+	case "::":
+		return LexemTypeScope
+
+	case "++":
+		return LexemTypeIncrement
+	case "--":
+		return LexemTypeDecrement
+
+	case "==":
+		return LexemTypeCompareEqual
+	case "!=":
+		return LexemTypeCompareNotEqual
+	case "<=":
+		return LexemTypeCompareLessOrEqual
+	case ">=":
+		return LexemTypeCompareGreaterOrEqual
+
+	case "&&":
+		return LexemTypeConjunction
+	case "||":
+		return LexemTypeDisjunction
+
+	case "+=":
+		return LexemTypeAssignAdd
+	case "-=":
+		return LexemTypeAssignSub
+	case "*=":
+		return LexemTypeAssignMul
+	case "/=":
+		return LexemTypeAssignDiv
+	case "%=":
+		return LexemTypeAssignRem
+	case "&=":
+		return LexemTypeAssignAnd
+	case "|=":
+		return LexemTypeAssignOr
+	case "^=":
+		return LexemTypeAssignXor
+
+	case "<<":
+		return LexemTypeShiftLeft
+	case ">>":
+		return LexemTypeShiftRight
+
+	case "->":
+		return LexemTypeRightArrow
+
+	case "$<":
+		return LexemTypeReferenceToPointer
+	case "$>":
+		return LexemTypePointerToReference
+	}
+
+	return LexemTypeNone
+}
+
+func textToLexem3(s string) LexemType {
+	switch s {
+	case "<=>":
+		return LexemTypeCompareOrder
+	case "<<=":
+		return LexemTypeAssignShiftLeft
+	case ">>=":
+		return LexemTypeAssignShiftRight
+	case "...":
+		return LexemTypeEllipsis
+	}
+
+	return LexemTypeNone
+}
@@ -0,0 +1,329 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSkipEscapeSequence(t *testing.T) {
+	test_cases := []struct {
+		text      string
+		remaining string // Text after escape sequence.
+		is_valid  bool
+	}{
+		{`\n`, "", true},
+		{`\t"`, `"`, true},
+		{`\\x`, "x", true},
+		{`\"`, "", true},
+		{`\'`, "", true},
+		{`\/`, "", true},
+		{`\0`, "", true},
+		{`\b\f`, `\f`, true},
+		{`\r`, "", true},
+		{`\u0041"`, `"`, true},
+		{`\uaBcD1`, "1", true},
+		{`\u004`, "", false},
+		{`\u12G4`, "", false},
+		{`\x41`, "", false},
+		{`\q`, "", false},
+		{`\1`, "", false},
+		{"\\\n", "", false},
+		{`\`, "", false},
+	}
+
+	for _, test_case := range test_cases {
+		s := test_case.text
+		err := skipEscapeSequence(&s)
+		if test_case.is_valid {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %s", test_case.text, err)
+			} else if s != test_case.remaining {
+				t.Errorf("%q: remaining text is %q, expected %q", test_case.text, s, test_case.remaining)
+			}
+		} else {
+			if err == nil {
+				t.Errorf("%q: expected error", test_case.text)
+			} else if s != test_case.text {
+				t.Errorf("%q: text is changed on error", test_case.text)
+			}
+		}
+	}
+}
+
+func TestStringLiteralErrors(t *testing.T) {
+	test_cases := []struct {
+		program string
+		error   string
+	}{
+		{`x = "abc`, "1:5: Unterminated string literal"},
+		{`x = "abc\`, "1:5: Unterminated string literal"},
+		{"x = \"ab\\q\";", "1:8: Invalid escape sequence \\q"},
+		{"a;\n  b = \"\\u12\";", "2:8: Expected 4 hex digits in escape sequence \\u"},
+		{"x = \"ab\\\n\";", "1:8: Unfinished escape sequence at end of line"},
+	}
+
+	for _, test_case := range test_cases {
+		_, err := SplitProgramIntoLexems(test_case.program)
+		if err == nil {
+			t.Errorf("%q: expected error", test_case.program)
+		} else if err.Error() != test_case.error {
+			t.Errorf("%q: error is %q, expected %q", test_case.program, err.Error(), test_case.error)
+		}
+	}
+}
+
+func TestMacroIdentifiers(t *testing.T) {
+	test_cases := []struct {
+		program string
+		lexems  []Lexem
+	}{
+		{"?name", []Lexem{{Type: LexemTypeMacroIdentifier, Text: "?name"}}},
+		{"??unique", []Lexem{{Type: LexemTypeMacroUniqueIdentifier, Text: "??unique"}}},
+		{"???x", []Lexem{{Type: LexemTypeQuestion, Text: "?"}, {Type: LexemTypeMacroUniqueIdentifier, Text: "??x"}}},
+		{"?e:expr", []Lexem{{Type: LexemTypeMacroIdentifier, Text: "?e"}, {Type: LexemTypeColon, Text: ":"}, {Type: LexemTypeIdentifier, Text: "expr"}}},
+		{"a ? b", []Lexem{{Type: LexemTypeIdentifier, Text: "a"}, {Type: LexemTypeQuestion, Text: "?"}, {Type: LexemTypeIdentifier, Text: "b"}}},
+		{"<? ?>", []Lexem{{Type: LexemTypeMacroBracketLeft, Text: "<?"}, {Type: LexemTypeMacroBracketRight, Text: "?>"}}},
+	}
+
+	for _, test_case := range test_cases {
+		lexems, err := SplitProgramIntoLexems(test_case.program)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test_case.program, err)
+			continue
+		}
+		lexems = lexems[:len(lexems)-1] // Remove end of file lexem.
+		if len(lexems) != len(test_case.lexems) {
+			t.Errorf("%q: got %d lexems, expected %d", test_case.program, len(lexems), len(test_case.lexems))
+			continue
+		}
+		for i := range lexems {
+			if lexems[i].Type != test_case.lexems[i].Type || lexems[i].Text != test_case.lexems[i].Text {
+				t.Errorf("%q: lexem %d is %q (type %d), expected %q (type %d)",
+					test_case.program, i, lexems[i].Text, lexems[i].Type, test_case.lexems[i].Text, test_case.lexems[i].Type)
+			}
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	test_cases := []struct {
+		text      string
+		number    string // Expected lexem text, empty for errors.
+		remaining string // Text after number or at error position.
+	}{
+		{"0", "0", ""},
+		{"123;", "123", ";"},
+		{"1.5", "1.5", ""},
+		{"1.foo", "1", ".foo"},
+		{"1.5.x", "1.5", ".x"},
+		{"12u8", "12u8", ""},
+		{"1.5f32", "1.5f32", ""},
+		{"1e3", "1e3", ""},
+		{"1e+3", "1e+3", ""},
+		{"1.5e-3f64", "1.5e-3f64", ""},
+		{"1eu", "1eu", ""}, // Suffix, starting with "e".
+		{"0b1010", "0b1010", ""},
+		{"0b1.01e-3", "0b1.01e-3", ""},
+		{"0o17", "0o17", ""},
+		{"0o7e2", "0o7e2", ""},
+		{"0x7F", "0x7F", ""},
+		{"0xdeadBEEFu32", "0xdeadBEEFu32", ""},
+		{"0xFFe2", "0xFFe2", ""}, // "e" is a hex digit.
+		{"0x1.8", "0x1.8", ""},
+		{"0XAB", "0XAB", ""}, // Uppercase prefix is not a prefix - "XAB" is a suffix.
+		{"0x", "", "0x"},
+		{"0xu8", "", "0xu8"},
+		{"0b", "", "0b"},
+		{"0b12", "", "2"},
+		{"0o78", "", "8"},
+		{"0o9", "", "0o9"},
+		{"1e+", "", ""},
+		{"1e-x", "", "x"},
+	}
+
+	for _, test_case := range test_cases {
+		s := test_case.text
+		lexem, err := parseNumber(&s)
+		if test_case.number != "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %s", test_case.text, err)
+			} else if lexem.Type != LexemTypeNumber || lexem.Text != test_case.number {
+				t.Errorf("%q: got %q, expected %q", test_case.text, lexem.Text, test_case.number)
+			}
+		} else if err == nil {
+			t.Errorf("%q: expected error, got %q", test_case.text, lexem.Text)
+		}
+		if s != test_case.remaining {
+			t.Errorf("%q: remaining text is %q, expected %q", test_case.text, s, test_case.remaining)
+		}
+	}
+}
+
+func TestNumberErrors(t *testing.T) {
+	test_cases := []struct {
+		program string
+		error   string
+	}{
+		{"a = 0x;", "1:5: Expected digits after number prefix"},
+		{"a = 0b102;", "1:9: Invalid digit for number with base 2"},
+		{"a = 1e+;", "1:8: Expected exponent digits"},
+	}
+
+	for _, test_case := range test_cases {
+		_, err := SplitProgramIntoLexems(test_case.program)
+		if err == nil {
+			t.Errorf("%q: expected error", test_case.program)
+		} else if err.Error() != test_case.error {
+			t.Errorf("%q: error is %q, expected %q", test_case.program, err.Error(), test_case.error)
+		}
+	}
+}
+
+func TestMultilineComments(t *testing.T) {
+	test_cases := []struct {
+		program string
+		comment string
+	}{
+		{"/* a */", "/* a */"},
+		{"/* a /* nested */ b */ x", "/* a /* nested */ b */"},
+		{"/* /* /* */ */ */", "/* /* /* */ */ */"},
+		// Line indentation is removed from continuation lines.
+		{"\t\t/* a\n\t\t   b\n\t\t*/", "/* a\n   b\n*/"},
+		// Only the common part of the indentation is removed.
+		{"\t\t/* a\n\tb\n    c */", "/* a\nb\n    c */"},
+		// Line endings are normalized.
+		{"/* a\r\n b\r c */", "/* a\n b\n c */"},
+	}
+
+	for _, test_case := range test_cases {
+		lexems, err := SplitProgramIntoLexems(test_case.program)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test_case.program, err)
+		} else if len(lexems) == 0 || lexems[0].Type != LexemTypeMultilineComment {
+			t.Errorf("%q: expected multiline comment", test_case.program)
+		} else if lexems[0].Text != test_case.comment {
+			t.Errorf("%q: got %q, expected %q", test_case.program, lexems[0].Text, test_case.comment)
+		}
+	}
+}
+
+func TestUnterminatedMultilineComment(t *testing.T) {
+	test_cases := []struct {
+		program string
+		error   string
+	}{
+		{"x /* a", "1:3: Unterminated multiline comment"},
+		{"x\n\t/* a /* b */\n", "2:2: Unterminated multiline comment"},
+	}
+
+	for _, test_case := range test_cases {
+		_, err := SplitProgramIntoLexems(test_case.program)
+		if err == nil {
+			t.Errorf("%q: expected error", test_case.program)
+		} else if err.Error() != test_case.error {
+			t.Errorf("%q: error is %q, expected %q", test_case.program, err.Error(), test_case.error)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	test_cases := []struct {
+		program    string
+		identifier string
+	}{
+		{"αβγ_δ1 ", "αβγ_δ1"},     // Greek.
+		{"Բարեւ+", "Բարեւ"},       // Armenian.
+		{"变量名;", "变量名"},           // CJK.
+		{"データ_2(", "データ_2"},       // Hiragana and katakana.
+		{"Ünicode", "Ünicode"},    // Latin with precomposed diacritics.
+		{"e\u0301x.", "e\u0301x"}, // Latin with combining acute accent.
+		{"क्षत्र ", "क्षत्र"},     // Devanagari with combining marks.
+		{"x\u203Fy,", "x\u203Fy"}, // Connector punctuation.
+	}
+
+	for _, test_case := range test_cases {
+		lexems, err := SplitProgramIntoLexems(test_case.program)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test_case.program, err)
+		} else if lexems[0].Type != LexemTypeIdentifier || lexems[0].Text != test_case.identifier {
+			t.Errorf("%q: got lexem %q, expected identifier %q", test_case.program, lexems[0].Text, test_case.identifier)
+		}
+	}
+}
+
+func TestByteOrderMark(t *testing.T) {
+	lexems, err := SplitProgramIntoLexems(ByteOrderMark + "a\nb")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// BOM is skipped and not counted as column.
+	expected := []SrcPos{{Offset: 3, Line: 1, Column: 1}, {Offset: 5, Line: 2, Column: 1}}
+	for i, pos := range expected {
+		if lexems[i].Span.Begin != pos {
+			t.Errorf("lexem %q: position is %+v, expected %+v", lexems[i].Text, lexems[i].Span.Begin, pos)
+		}
+	}
+}
+
+func TestUnexpectedCharacters(t *testing.T) {
+	test_cases := []struct {
+		program string
+		error   string
+	}{
+		// Identifiers can't start with combining marks and digits of other scripts.
+		{"a = \u0301x;", "1:5: Unexpected character with code 769"},
+		{"a = ١;", "1:5: Unexpected character with code 1633"},
+		// BOM is allowed only at start.
+		{"a\n\uFEFFb", "2:1: Unexpected invisible character U+FEFF (byte order mark)"},
+		{"\uFEFF\uFEFFa", "1:1: Unexpected invisible character U+FEFF (byte order mark)"},
+		{"a\u200Bb", "1:2: Unexpected invisible character U+200B (zero width space)"},
+		{"a\u00A0= b", "1:2: Unexpected invisible character U+00A0 (no-break space)"},
+		{"a =\u202Eb", "1:4: Unexpected invisible character U+202E (right-to-left override)"},
+		{"a\u2028b", "1:2: Unexpected invisible character U+2028 (line separator)"},
+		{"a\u0085b", "1:2: Unexpected invisible character U+0085 (next line)"},
+		{"a = b\u037E", "1:6: Unexpected character U+037E (greek question mark), did you mean ';'?"},
+		{"a \u2212 b;", "1:3: Unexpected character U+2212 (minus sign), did you mean '-'?"},
+		{"f\uFF08x);", "1:2: Unexpected character U+FF08 (fullwidth left parenthesis), did you mean '('?"},
+		{"a = \u201Cs\u201D;", "1:5: Unexpected character U+201C (left double quotation mark), did you mean '\"'?"},
+		{"a = 1 \u20AC", "1:7: Unexpected character with code 8364"},
+	}
+
+	for _, test_case := range test_cases {
+		_, err := SplitProgramIntoLexems(test_case.program)
+		if err == nil {
+			t.Errorf("%q: expected error", test_case.program)
+		} else if err.Error() != test_case.error {
+			t.Errorf("%q: error is %q, expected %q", test_case.program, err.Error(), test_case.error)
+		}
+	}
+}
+
+func TestUnicodeNewlinesInComments(t *testing.T) {
+	// Unicode line separators are not newlines, so they don't end line comments and don't change lines numbering.
+	for _, separator := range []string{"\u0085", "\u2028", "\u2029"} {
+		program := "// a" + separator + "b\n/* c" + separator + "d */ \"e" + separator + "\" x"
+		lexems, err := SplitProgramIntoLexems(program)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", program, err)
+			continue
+		}
+
+		expected := []struct {
+			text   string
+			line   uint
+			column uint
+		}{
+			{"// a" + separator + "b", 1, 1},
+			{"/* c" + separator + "d */", 2, 1},
+			{"\"e" + separator + "\"", 2, 11},
+			{"x", 2, 16},
+		}
+		for i, lexem := range expected {
+			pos := SrcPos{Offset: uint(strings.Index(program, lexem.text)), Line: lexem.line, Column: lexem.column}
+			if lexems[i].Text != lexem.text || lexems[i].Span.Begin != pos {
+				t.Errorf("%q: lexem %d is %+v, expected %q at %+v", program, i, lexems[i], lexem.text, pos)
+			}
+		}
+	}
+}
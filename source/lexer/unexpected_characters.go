@@ -0,0 +1,78 @@
+package lexer
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// Byte order mark, allowed only at start of the program.
+const ByteOrderMark = "\uFEFF"
+
+var invisibleCharacterNames = map[rune]string{
+	0x0085: "next line",
+	0x00A0: "no-break space",
+	0x00AD: "soft hyphen",
+	0x200B: "zero width space",
+	0x200C: "zero width non-joiner",
+	0x200D: "zero width joiner",
+	0x200E: "left-to-right mark",
+	0x200F: "right-to-left mark",
+	0x2028: "line separator",
+	0x2029: "paragraph separator",
+	0x202A: "left-to-right embedding",
+	0x202B: "right-to-left embedding",
+	0x202C: "pop directional formatting",
+	0x202D: "left-to-right override",
+	0x202E: "right-to-left override",
+	0x2060: "word joiner",
+	0x2066: "left-to-right isolate",
+	0x2067: "right-to-left isolate",
+	0x2068: "first strong isolate",
+	0x2069: "pop directional isolate",
+	0x3000: "ideographic space",
+	0xFEFF: "byte order mark",
+}
+
+type confusableCharacter struct {
+	name        string
+	replacement rune
+}
+
+// Characters looking like punctuation of the language.
+var confusableCharacters = map[rune]confusableCharacter{
+	0x00D7: {"multiplication sign", '*'},
+	0x037E: {"greek question mark", ';'},
+	0x2010: {"hyphen", '-'},
+	0x2011: {"non-breaking hyphen", '-'},
+	0x2013: {"en dash", '-'},
+	0x2014: {"em dash", '-'},
+	0x2018: {"left single quotation mark", '\''},
+	0x2019: {"right single quotation mark", '\''},
+	0x201C: {"left double quotation mark", '"'},
+	0x201D: {"right double quotation mark", '"'},
+	0x2212: {"minus sign", '-'},
+	0x2215: {"division slash", '/'},
+	0x2236: {"ratio", ':'},
+	0xFF08: {"fullwidth left parenthesis", '('},
+	0xFF09: {"fullwidth right parenthesis", ')'},
+	0xFF0C: {"fullwidth comma", ','},
+	0xFF1A: {"fullwidth colon", ':'},
+	0xFF1B: {"fullwidth semicolon", ';'},
+	0xFF1D: {"fullwidth equals sign", '='},
+}
+
+// Get error message for a character, which can't start any lexem.
+func describeUnexpectedCharacter(c rune) string {
+	if confusable, ok := confusableCharacters[c]; ok {
+		return fmt.Sprintf("Unexpected character U+%04X (%s), did you mean %q?", c, confusable.name, confusable.replacement)
+	}
+
+	if name, ok := invisibleCharacterNames[c]; ok {
+		return fmt.Sprintf("Unexpected invisible character U+%04X (%s)", c, name)
+	}
+	if unicode.IsSpace(c) || unicode.Is(unicode.Cf, c) {
+		return fmt.Sprintf("Unexpected invisible character U+%04X", c)
+	}
+
+	return fmt.Sprintf("Unexpected character with code %d", int(c))
+}
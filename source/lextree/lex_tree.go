@@ -0,0 +1,132 @@
+package lextree
+
+import (
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+)
+
+// Do not perform proper syntax analysis.
+// It's not possible due to complication with macros.
+// Build simple tree structure instead - where lexems inside paired symbols ( (), [], {}, <//>, <??>) are grouped together.
+
+type LexTreeNodeList = []LexTreeNode
+
+type LexTreeNode struct {
+	Lexem         lexer.Lexem
+	SubElements   LexTreeNodeList
+	TrailingLexem lexer.Lexem
+}
+
+func BuildLexTree(lexems []lexer.Lexem) (LexTreeNodeList, error) {
+	return parseLexTree_r(&lexems, lexer.LexemTypeEndOfFile)
+}
+
+// Parse until specified end lexem.
+func parseLexTree_r(lexems *[]lexer.Lexem, end_lexem_type lexer.LexemType) (LexTreeNodeList, error) {
+	result := make([]LexTreeNode, 0)
+
+	for len(*lexems) > 0 {
+		lexem := &(*lexems)[0]
+
+		if lexem.Type == end_lexem_type {
+			break
+		}
+
+		*lexems = (*lexems)[1:]
+
+		if lexem.Type == lexer.LexemTypeBraceLeft {
+
+			sub_elements, err := parseLexTree_r(lexems, lexer.LexemTypeBraceRight)
+			if err != nil {
+				return nil, err
+			}
+
+			node := LexTreeNode{Lexem: *lexem, SubElements: sub_elements}
+
+			if !(len(*lexems) > 0 && (*lexems)[0].Type == lexer.LexemTypeBraceRight) {
+				return nil, lexer.NewSrcError(lexem.Span.Begin, "non-matching } for {")
+			}
+
+			node.TrailingLexem = (*lexems)[0]
+			*lexems = (*lexems)[1:]
+
+			result = append(result, node)
+
+		} else if lexem.Type == lexer.LexemTypeBracketLeft {
+
+			sub_elements, err := parseLexTree_r(lexems, lexer.LexemTypeBracketRight)
+			if err != nil {
+				return nil, err
+			}
+
+			node := LexTreeNode{Lexem: *lexem, SubElements: sub_elements}
+
+			if !(len(*lexems) > 0 && (*lexems)[0].Type == lexer.LexemTypeBracketRight) {
+				return nil, lexer.NewSrcError(lexem.Span.Begin, "non-matching ) for (")
+			}
+
+			node.TrailingLexem = (*lexems)[0]
+			*lexems = (*lexems)[1:]
+
+			result = append(result, node)
+
+		} else if lexem.Type == lexer.LexemTypeSquareBracketLeft {
+
+			sub_elements, err := parseLexTree_r(lexems, lexer.LexemTypeSquareBracketRight)
+			if err != nil {
+				return nil, err
+			}
+
+			node := LexTreeNode{Lexem: *lexem, SubElements: sub_elements}
+
+			if !(len(*lexems) > 0 && (*lexems)[0].Type == lexer.LexemTypeSquareBracketRight) {
+				return nil, lexer.NewSrcError(lexem.Span.Begin, "non-matching ] for [")
+			}
+
+			node.TrailingLexem = (*lexems)[0]
+			*lexems = (*lexems)[1:]
+
+			result = append(result, node)
+
+		} else if lexem.Type == lexer.LexemTypeTemplateBracketLeft {
+
+			sub_elements, err := parseLexTree_r(lexems, lexer.LexemTypeTemplateBracketRight)
+			if err != nil {
+				return nil, err
+			}
+
+			node := LexTreeNode{Lexem: *lexem, SubElements: sub_elements}
+
+			if !(len(*lexems) > 0 && (*lexems)[0].Type == lexer.LexemTypeTemplateBracketRight) {
+				return nil, lexer.NewSrcError(lexem.Span.Begin, "non-matching /> for </")
+			}
+
+			node.TrailingLexem = (*lexems)[0]
+			*lexems = (*lexems)[1:]
+
+			result = append(result, node)
+
+		} else if lexem.Type == lexer.LexemTypeMacroBracketLeft {
+
+			sub_elements, err := parseLexTree_r(lexems, lexer.LexemTypeMacroBracketRight)
+			if err != nil {
+				return nil, err
+			}
+
+			node := LexTreeNode{Lexem: *lexem, SubElements: sub_elements}
+
+			if !(len(*lexems) > 0 && (*lexems)[0].Type == lexer.LexemTypeMacroBracketRight) {
+				return nil, lexer.NewSrcError(lexem.Span.Begin, "non-matching ?> for <?")
+			}
+
+			node.TrailingLexem = (*lexems)[0]
+			*lexems = (*lexems)[1:]
+
+			result = append(result, node)
+
+		} else {
+			result = append(result, LexTreeNode{Lexem: *lexem})
+		}
+	}
+
+	return result, nil
+}
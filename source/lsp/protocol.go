@@ -0,0 +1,115 @@
+package lsp
+
+import (
+	"encoding/json"
+)
+
+// Subset of Language Server Protocol structures, necessary for formatting.
+// See https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/.
+
+type requestMessage struct {
+	JsonRpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id,omitempty"` // Nil for notifications.
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type responseMessage struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error codes.
+const (
+	errorCodeParseError     = -32700
+	errorCodeInvalidParams  = -32602
+	errorCodeMethodNotFound = -32601
+	errorCodeRequestFailed  = -32803
+)
+
+type Position struct {
+	Line      uint `json:"line"`
+	Character uint `json:"character"` // In UTF-16 code units.
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+type textDocumentIdentifier struct {
+	Uri string `json:"uri"`
+}
+
+type textDocumentItem struct {
+	Uri  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type didOpenTextDocumentParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type textDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+type didChangeTextDocumentParams struct {
+	TextDocument   textDocumentIdentifier           `json:"textDocument"`
+	ContentChanges []textDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+type didCloseTextDocumentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type documentFormattingParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type documentRangeFormattingParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+}
+
+type documentOnTypeFormattingParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+	Ch           string                 `json:"ch"`
+}
+
+type documentOnTypeFormattingOptions struct {
+	FirstTriggerCharacter string   `json:"firstTriggerCharacter"`
+	MoreTriggerCharacter  []string `json:"moreTriggerCharacter"`
+}
+
+type serverCapabilities struct {
+	TextDocumentSync                 int                             `json:"textDocumentSync"`
+	DocumentFormattingProvider       bool                            `json:"documentFormattingProvider"`
+	DocumentRangeFormattingProvider  bool                            `json:"documentRangeFormattingProvider"`
+	DocumentOnTypeFormattingProvider documentOnTypeFormattingOptions `json:"documentOnTypeFormattingProvider"`
+}
+
+// Full document synchronization.
+const textDocumentSyncKindFull = 1
+
+type serverInfo struct {
+	Name string `json:"name"`
+}
+
+type initializeResult struct {
+	Capabilities serverCapabilities `json:"capabilities"`
+	ServerInfo   serverInfo         `json:"serverInfo"`
+}
@@ -0,0 +1,327 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/format"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/lexer"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Returned by Run if "exit" notification is received without prior "shutdown" request.
+var ErrExitWithoutShutdown = errors.New("exit without shutdown")
+
+// Function for obtaining formatting options for given file.
+// File name is empty for documents which are not files.
+type OptionsGetter func(file_name string) (format.Options, error)
+
+// Language server, which supports only formatting requests.
+// Requests are processed sequentially.
+type Server struct {
+	reader      *bufio.Reader
+	writer      io.Writer
+	get_options OptionsGetter
+	documents   map[string]string // Texts of opened documents by URI.
+	shutdown    bool
+}
+
+func NewServer(reader io.Reader, writer io.Writer, get_options OptionsGetter) *Server {
+	return &Server{
+		reader:      bufio.NewReader(reader),
+		writer:      writer,
+		get_options: get_options,
+		documents:   make(map[string]string)}
+}
+
+// Process messages until "exit" notification or end of input.
+// Returns error if "exit" is received without prior "shutdown" request or if input is broken.
+func (server *Server) Run() error {
+	for {
+		content, err := server.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		request := requestMessage{}
+		err = json.Unmarshal(content, &request)
+		if err != nil {
+			server.writeResponse(json.RawMessage("null"), nil, &responseError{Code: errorCodeParseError, Message: err.Error()})
+			continue
+		}
+
+		if request.Method == "exit" {
+			if !server.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+
+		result, response_error := server.handleMessage(&request)
+
+		if request.Id != nil {
+			server.writeResponse(*request.Id, result, response_error)
+		}
+	}
+}
+
+// Returns result or error for requests. Results of notifications are ignored.
+func (server *Server) handleMessage(request *requestMessage) (any, *responseError) {
+
+	switch request.Method {
+
+	case "initialize":
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync:                textDocumentSyncKindFull,
+				DocumentFormattingProvider:      true,
+				DocumentRangeFormattingProvider: true,
+				DocumentOnTypeFormattingProvider: documentOnTypeFormattingOptions{
+					FirstTriggerCharacter: ";",
+					MoreTriggerCharacter:  []string{"}"},
+				},
+			},
+			ServerInfo: serverInfo{Name: "Ü formatter"},
+		}, nil
+
+	case "shutdown":
+		server.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		params := didOpenTextDocumentParams{}
+		if json.Unmarshal(request.Params, &params) == nil {
+			server.documents[params.TextDocument.Uri] = params.TextDocument.Text
+		}
+		return nil, nil
+
+	case "textDocument/didChange":
+		params := didChangeTextDocumentParams{}
+		if json.Unmarshal(request.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			// Only full synchronization is supported, so use last change.
+			server.documents[params.TextDocument.Uri] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		}
+		return nil, nil
+
+	case "textDocument/didClose":
+		params := didCloseTextDocumentParams{}
+		if json.Unmarshal(request.Params, &params) == nil {
+			delete(server.documents, params.TextDocument.Uri)
+		}
+		return nil, nil
+
+	case "textDocument/formatting":
+		params := documentFormattingParams{}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, &responseError{Code: errorCodeInvalidParams, Message: err.Error()}
+		}
+		return server.formatDocument(params.TextDocument.Uri, nil)
+
+	case "textDocument/rangeFormatting":
+		params := documentRangeFormattingParams{}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, &responseError{Code: errorCodeInvalidParams, Message: err.Error()}
+		}
+		return server.formatDocumentRange(params.TextDocument.Uri, params.Range)
+
+	case "textDocument/onTypeFormatting":
+		params := documentOnTypeFormattingParams{}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, &responseError{Code: errorCodeInvalidParams, Message: err.Error()}
+		}
+		return server.formatOnType(params.TextDocument.Uri, params.Position, params.Ch)
+	}
+
+	if strings.HasPrefix(request.Method, "$/") || request.Id == nil {
+		// Ignore optional and unknown notifications.
+		return nil, nil
+	}
+
+	return nil, &responseError{Code: errorCodeMethodNotFound, Message: "method not found: " + request.Method}
+}
+
+// Format given lines ranges or whole document, if ranges list is nil.
+func (server *Server) formatDocument(uri string, ranges []format.LineRange) ([]TextEdit, *responseError) {
+
+	text, ok := server.documents[uri]
+	if !ok {
+		return nil, &responseError{Code: errorCodeRequestFailed, Message: "document is not opened: " + uri}
+	}
+
+	options, err := server.get_options(uriToFileName(uri))
+	if err != nil {
+		return nil, &responseError{Code: errorCodeRequestFailed, Message: err.Error()}
+	}
+
+	text_formatted, err := format.SourceLines([]byte(text), options, ranges)
+	if err != nil {
+		return nil, &responseError{Code: errorCodeRequestFailed, Message: err.Error()}
+	}
+
+	lexems, err := lexer.SplitProgramIntoLexems(text)
+	if err != nil {
+		return nil, &responseError{Code: errorCodeRequestFailed, Message: err.Error()}
+	}
+
+	// Fails if lexems of formatted text don't match the source - never change meaning of the document.
+	whitespace_edits, err := format.ComputeWhitespaceEdits(text, lexems, string(text_formatted), &options)
+	if err != nil {
+		return nil, &responseError{Code: errorCodeRequestFailed, Message: err.Error()}
+	}
+
+	return convertTextEdits(text, whitespace_edits), nil
+}
+
+// Convert offset-based edits into LSP edits.
+func convertTextEdits(text string, edits []format.TextEdit) []TextEdit {
+	result := make([]TextEdit, 0, len(edits))
+	for _, edit := range edits {
+		result = append(
+			result,
+			TextEdit{
+				Range: Range{
+					Start: OffsetToPosition(text, int(edit.Offset)),
+					End:   OffsetToPosition(text, int(edit.Offset+edit.Length))},
+				NewText: edit.Replacement})
+	}
+	return result
+}
+
+func (server *Server) formatDocumentRange(uri string, r Range) ([]TextEdit, *responseError) {
+
+	text := server.documents[uri]
+
+	begin_offset := PositionToOffset(text, r.Start)
+	end_offset := PositionToOffset(text, r.End)
+	if end_offset > begin_offset && r.End.Character == 0 {
+		// Do not include line where selection ends at its start.
+		end_offset--
+	}
+
+	line_starts := lexer.GetLineStartOffsets(text)
+	return server.formatDocument(
+		uri,
+		[]format.LineRange{{First: offsetToLexerLine(line_starts, begin_offset), Last: offsetToLexerLine(line_starts, end_offset)}})
+}
+
+// Format line with typed ";" or block with typed "}".
+// Returns no edits if document can't be lexed, since it is normal for text in process of typing.
+func (server *Server) formatOnType(uri string, pos Position, ch string) ([]TextEdit, *responseError) {
+
+	text := server.documents[uri]
+
+	lexems, err := lexer.SplitProgramIntoLexems(text)
+	if err != nil {
+		return []TextEdit{}, nil
+	}
+
+	// Find typed lexem, which ends at given position.
+	offset := uint(PositionToOffset(text, pos))
+	lexem_index := -1
+	for i := range lexems {
+		if lexems[i].Span.End.Offset == offset && lexems[i].Text == ch {
+			lexem_index = i
+			break
+		}
+	}
+	if lexem_index < 0 {
+		return []TextEdit{}, nil
+	}
+
+	line_range := format.LineRange{First: lexems[lexem_index].Span.Begin.Line, Last: lexems[lexem_index].Span.Begin.Line}
+
+	if lexems[lexem_index].Type == lexer.LexemTypeBraceRight {
+		// Search for matching "{".
+		depth := 0
+		for i := lexem_index; i >= 0; i-- {
+			if lexems[i].Type == lexer.LexemTypeBraceRight {
+				depth++
+			} else if lexems[i].Type == lexer.LexemTypeBraceLeft {
+				depth--
+				if depth == 0 {
+					line_range.First = lexems[i].Span.Begin.Line
+					break
+				}
+			}
+		}
+	}
+
+	edits, response_error := server.formatDocument(uri, []format.LineRange{line_range})
+	if response_error != nil {
+		return []TextEdit{}, nil
+	}
+	return edits, nil
+}
+
+// Get line number (starting from 1) for given offset.
+func offsetToLexerLine(line_starts []uint, offset int) uint {
+	return uint(sort.Search(len(line_starts), func(i int) bool { return int(line_starts[i]) > offset }))
+}
+
+// Returns empty string for non-file URIs.
+func uriToFileName(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return u.Path
+}
+
+func (server *Server) readMessage() ([]byte, error) {
+	content_length := -1
+
+	// Read headers.
+	for {
+		line, err := server.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && len(line) == 0 {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			content_length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %s", value)
+			}
+		}
+	}
+
+	if content_length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+
+	content := make([]byte, content_length)
+	_, err := io.ReadFull(server.reader, content)
+	return content, err
+}
+
+func (server *Server) writeResponse(id json.RawMessage, result any, response_error *responseError) {
+	response := responseMessage{JsonRpc: "2.0", Id: id, Error: response_error}
+	if response_error == nil {
+		result_json, err := json.Marshal(result)
+		if err != nil {
+			response.Error = &responseError{Code: errorCodeRequestFailed, Message: err.Error()}
+		} else {
+			response.Result = result_json
+		}
+	}
+
+	content, _ := json.Marshal(response)
+	fmt.Fprintf(server.writer, "Content-Length: %d\r\n\r\n%s", len(content), content)
+}
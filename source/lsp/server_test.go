@@ -0,0 +1,164 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/Panzerschrek/U-00DC-Formatter/source/format"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func makeMessage(content string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(content), content)
+}
+
+func getTestOptions(file_name string) (format.Options, error) {
+	return format.GetDefaultOptions(), nil
+}
+
+// Run server with given input and return responses.
+func runServer(t *testing.T, input string) ([]responseMessage, error) {
+	output := bytes.Buffer{}
+	err := NewServer(strings.NewReader(input), &output, getTestOptions).Run()
+
+	responses := make([]responseMessage, 0)
+	reader := &Server{reader: bufio.NewReader(&output)}
+	for {
+		content, read_err := reader.readMessage()
+		if read_err != nil {
+			break
+		}
+		response := responseMessage{}
+		if unmarshal_err := json.Unmarshal(content, &response); unmarshal_err != nil {
+			t.Fatalf("invalid response %q: %s", content, unmarshal_err)
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, err
+}
+
+func TestReadMessage(t *testing.T) {
+	test_cases := []struct {
+		input   string
+		content string
+		error   string
+	}{
+		{"Content-Length: 2\r\n\r\n{}", "{}", ""},
+		// Header name is case-insensitive, other headers are ignored.
+		{"content-length:2\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n{}", "{}", ""},
+		// Bare "\n" line ends are accepted.
+		{"Content-Length: 2\n\n{}", "{}", ""},
+		// Content is not limited by line ends.
+		{"Content-Length: 5\r\n\r\n{\r\n}\n", "{\r\n}\n", ""},
+		{"Content-Length: 2\r\n\r\n{}Content-Length: 2", "{}", ""},
+		{"Content-Type: text\r\n\r\n{}", "", "missing Content-Length header"},
+		{"Content-Length: x\r\n\r\n{}", "", "invalid Content-Length:  x"},
+		{"Content-Length: 10\r\n\r\n{}", "", "unexpected EOF"},
+		{"", "", "EOF"},
+	}
+
+	for _, test_case := range test_cases {
+		server := NewServer(strings.NewReader(test_case.input), nil, getTestOptions)
+		content, err := server.readMessage()
+		if test_case.error != "" {
+			if err == nil || err.Error() != test_case.error {
+				t.Errorf("%q: got error %v, expected %q", test_case.input, err, test_case.error)
+			}
+		} else if err != nil {
+			t.Errorf("%q: unexpected error: %s", test_case.input, err)
+		} else if string(content) != test_case.content {
+			t.Errorf("%q: got content %q, expected %q", test_case.input, content, test_case.content)
+		}
+	}
+}
+
+func TestExit(t *testing.T) {
+	_, err := runServer(t, makeMessage(`{"jsonrpc":"2.0","method":"exit"}`))
+	if err != ErrExitWithoutShutdown {
+		t.Errorf("got error %v, expected %v", err, ErrExitWithoutShutdown)
+	}
+
+	_, err = runServer(t, makeMessage(`{"jsonrpc":"2.0","id":1,"method":"shutdown"}`)+makeMessage(`{"jsonrpc":"2.0","method":"exit"}`))
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// End of input.
+	_, err = runServer(t, "")
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestFormattingSession(t *testing.T) {
+	text := "fn f()\n{\n\tif(a)\n\t{\n\t\tb  ;\n\t}\n\tc  ;\n}\n"
+
+	open_params, _ := json.Marshal(didOpenTextDocumentParams{TextDocument: textDocumentItem{Uri: "untitled:test", Text: text}})
+
+	input :=
+		makeMessage(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`) +
+			makeMessage(`{"jsonrpc":"2.0","method":"initialized","params":{}}`) +
+			makeMessage(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":`+string(open_params)+`}`) +
+			// "}" typed at end of "if" block.
+			makeMessage(`{"jsonrpc":"2.0","id":2,"method":"textDocument/onTypeFormatting","params":{"textDocument":{"uri":"untitled:test"},"position":{"line":5,"character":2},"ch":"}","options":{}}}`) +
+			// ";" typed at line with "c".
+			makeMessage(`{"jsonrpc":"2.0","id":3,"method":"textDocument/onTypeFormatting","params":{"textDocument":{"uri":"untitled:test"},"position":{"line":6,"character":5},"ch":";","options":{}}}`) +
+			// Position doesn't match typed character.
+			makeMessage(`{"jsonrpc":"2.0","id":4,"method":"textDocument/onTypeFormatting","params":{"textDocument":{"uri":"untitled:test"},"position":{"line":6,"character":4},"ch":";","options":{}}}`) +
+			makeMessage(`{"jsonrpc":"2.0","id":5,"method":"textDocument/formatting","params":{"textDocument":{"uri":"untitled:test"},"options":{}}}`) +
+			makeMessage(`{"jsonrpc":"2.0","id":6,"method":"textDocument/formatting","params":{"textDocument":{"uri":"untitled:other"},"options":{}}}`) +
+			makeMessage(`{"jsonrpc":"2.0","id":7,"method":"unknown/method"}`) +
+			makeMessage(`{"jsonrpc":"2.0","id":8,"method":"shutdown"}`) +
+			makeMessage(`{"jsonrpc":"2.0","method":"exit"}`)
+
+	responses, err := runServer(t, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(responses) != 8 {
+		t.Fatalf("got %d responses, expected 8", len(responses))
+	}
+
+	for i, response := range responses {
+		if string(response.Id) != fmt.Sprint(i+1) {
+			t.Errorf("response %d: got id %s", i, response.Id)
+		}
+	}
+
+	b_edit := TextEdit{Range: Range{Start: Position{4, 3}, End: Position{4, 5}}, NewText: ""}
+	c_edit := TextEdit{Range: Range{Start: Position{6, 2}, End: Position{6, 4}}, NewText: ""}
+	if_edit_0 := TextEdit{Range: Range{Start: Position{2, 4}, End: Position{2, 4}}, NewText: " "}
+	if_edit_1 := TextEdit{Range: Range{Start: Position{2, 5}, End: Position{2, 5}}, NewText: " "}
+
+	expected_edits := map[int][]TextEdit{
+		// Whole block with "}", including line with "{", but not line with "if".
+		1: {b_edit},
+		2: {c_edit},
+		3: {},
+		4: {if_edit_0, if_edit_1, b_edit, c_edit},
+	}
+
+	for i, expected := range expected_edits {
+		if responses[i].Error != nil {
+			t.Errorf("response %d: unexpected error: %s", i+1, responses[i].Error.Message)
+			continue
+		}
+		edits := []TextEdit{}
+		if err := json.Unmarshal(responses[i].Result, &edits); err != nil {
+			t.Errorf("response %d: invalid result %s", i+1, responses[i].Result)
+		} else if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("response %d: got edits %+v, expected %+v", i+1, edits, expected)
+		}
+	}
+
+	if responses[5].Error == nil || responses[5].Error.Code != errorCodeRequestFailed {
+		t.Errorf("expected error for not opened document, got %+v", responses[5])
+	}
+	if responses[6].Error == nil || responses[6].Error.Code != errorCodeMethodNotFound {
+		t.Errorf("expected error for unknown method, got %+v", responses[6])
+	}
+}
@@ -0,0 +1,62 @@
+package lsp
+
+import (
+	"unicode/utf8"
+)
+
+// Convert byte offset in text into LSP position.
+// Lines are separated by "\n", "\r\n" or "\r", characters are counted in UTF-16 code units.
+func OffsetToPosition(text string, offset int) Position {
+	pos := Position{}
+	for i := 0; i < offset && i < len(text); {
+		c, c_size := utf8.DecodeRuneInString(text[i:])
+		if c == '\n' || (c == '\r' && !(i+1 < len(text) && text[i+1] == '\n')) {
+			pos.Line++
+			pos.Character = 0
+		} else if c != '\r' {
+			pos.Character += utf16Length(c)
+		}
+		i += c_size
+	}
+	return pos
+}
+
+// Convert LSP position into byte offset in text.
+// Positions beyond line end are clamped to line end, positions beyond text end are clamped to text end.
+func PositionToOffset(text string, pos Position) int {
+	i := 0
+
+	// Skip lines.
+	for line := uint(0); line < pos.Line; line++ {
+		for i < len(text) && text[i] != '\n' && text[i] != '\r' {
+			i++
+		}
+		if i == len(text) {
+			return i
+		}
+		if text[i] == '\r' && i+1 < len(text) && text[i+1] == '\n' {
+			i++
+		}
+		i++
+	}
+
+	// Skip characters.
+	character := uint(0)
+	for i < len(text) && character < pos.Character {
+		c, c_size := utf8.DecodeRuneInString(text[i:])
+		if c == '\n' || c == '\r' {
+			break
+		}
+		character += utf16Length(c)
+		i += c_size
+	}
+
+	return i
+}
+
+func utf16Length(c rune) uint {
+	if c >= 0x10000 {
+		return 2
+	}
+	return 1
+}
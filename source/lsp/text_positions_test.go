@@ -0,0 +1,71 @@
+package lsp
+
+import (
+	"testing"
+)
+
+func TestPositionConversions(t *testing.T) {
+	test_cases := []struct {
+		text     string
+		offset   int
+		position Position
+	}{
+		{"", 0, Position{0, 0}},
+		{"abc", 2, Position{0, 2}},
+		{"abc", 3, Position{0, 3}},
+		{"ab\ncd", 3, Position{1, 0}},
+		{"ab\ncd", 4, Position{1, 1}},
+		// CRLF line ends.
+		{"ab\r\ncd", 4, Position{1, 0}},
+		{"ab\r\ncd", 5, Position{1, 1}},
+		{"\r\n\r\nx", 4, Position{2, 0}},
+		// CR line ends.
+		{"ab\rcd", 3, Position{1, 0}},
+		{"ab\rcd", 5, Position{1, 2}},
+		{"\r\rx", 2, Position{2, 0}},
+		// Two-byte UTF-8, single UTF-16 code unit.
+		{"ёж = 1", 4, Position{0, 2}},
+		// Three-byte UTF-8, single UTF-16 code unit.
+		{"\n字x", 4, Position{1, 1}},
+		// Four-byte UTF-8, UTF-16 surrogate pair.
+		{"a😀b", 5, Position{0, 3}},
+		{"a😀b", 6, Position{0, 4}},
+		{"😀\r\n😀", 10, Position{1, 2}},
+	}
+
+	for _, test_case := range test_cases {
+		position := OffsetToPosition(test_case.text, test_case.offset)
+		if position != test_case.position {
+			t.Errorf("%q, offset %d: got position %v, expected %v", test_case.text, test_case.offset, position, test_case.position)
+		}
+
+		offset := PositionToOffset(test_case.text, test_case.position)
+		if offset != test_case.offset {
+			t.Errorf("%q, position %v: got offset %d, expected %d", test_case.text, test_case.position, offset, test_case.offset)
+		}
+	}
+}
+
+func TestPositionToOffsetClamping(t *testing.T) {
+	test_cases := []struct {
+		text     string
+		position Position
+		offset   int
+	}{
+		// Beyond line end.
+		{"ab\ncd", Position{0, 10}, 2},
+		{"ab\r\ncd", Position{0, 10}, 2},
+		{"ab\rcd", Position{0, 3}, 2},
+		// Beyond text end.
+		{"ab\ncd", Position{1, 10}, 5},
+		{"ab\ncd", Position{5, 0}, 5},
+		{"ab\n", Position{1, 0}, 3},
+	}
+
+	for _, test_case := range test_cases {
+		offset := PositionToOffset(test_case.text, test_case.position)
+		if offset != test_case.offset {
+			t.Errorf("%q, position %v: got offset %d, expected %d", test_case.text, test_case.position, offset, test_case.offset)
+		}
+	}
+}